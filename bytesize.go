@@ -0,0 +1,187 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*	Размер в байтах. В конфигурационнике задается числом байт или строкой с единицей
+**	измерения SI (kB, MB, GB ... - степени 1000) или IEC (KiB, MiB, GiB ... - степени 1024),
+**	например 512MiB, 10GB или 1.5 GiB. Тот же формат принимают тэги min, max, enum и default  */
+type ByteSize uint64
+
+/*	Единица измерения размера  */
+type byteUnit struct {
+	name string
+	size uint64
+}
+
+/*	Единицы в порядке возрастания. Для разбора регистр не важен, а однобуквенные
+**	сокращения (k, M, G, Ki, Mi ...) совпадают с полными именами  */
+var byteUnits = []byteUnit{
+	{"B", 1},
+	{"kB", 1e3},
+	{"KiB", 1 << 10},
+	{"MB", 1e6},
+	{"MiB", 1 << 20},
+	{"GB", 1e9},
+	{"GiB", 1 << 30},
+	{"TB", 1e12},
+	{"TiB", 1 << 40},
+	{"PB", 1e15},
+	{"PiB", 1 << 50},
+	{"EB", 1e18},
+	{"EiB", 1 << 60},
+}
+
+var byteSizeType = reflect.TypeOf(ByteSize(0))
+
+/*	Ошибки разбора размера: строка не является числом с единицей измерения
+**	или содержит отрицательный размер  */
+var (
+	errByteSizeSyntax   = errors.New("invalid byte size")
+	errByteSizeNegative = errors.New("negative byte size")
+)
+
+/*	Разбор размера вида 512MiB, 10GB, 1.5 GiB или 1024. Дробная запись допускается,
+**	если в итоге получается целое число байт. Выход за пределы uint64 возвращает strconv.ErrRange  */
+func ParseByteSize(text string) (ByteSize, error) {
+	text = strings.TrimSpace(text)
+	/*	Отрицательный размер отличается от опечатки: сама запись без знака корректна  */
+	if strings.HasPrefix(text, "-") == true {
+		size, err := ParseByteSize(text[1:])
+		if err == nil && size == 0 {
+			return 0, nil
+		}
+		if err == nil || errors.Is(err, strconv.ErrRange) == true {
+			return 0, errByteSizeNegative
+		}
+		return 0, errByteSizeSyntax
+	}
+	split := strings.IndexFunc(text, func(char rune) bool {
+		return (char < '0' || char > '9') && char != '.' && char != '_'
+	})
+	number, unitName := text, ""
+	if split >= 0 {
+		number, unitName = text[:split], strings.TrimSpace(text[split:])
+	}
+	unit, exists := lookupByteUnit(unitName)
+	if number == "" || exists == false || strings.HasPrefix(number, "_") == true || strings.HasSuffix(number, "_") == true {
+		return 0, errByteSizeSyntax
+	}
+	value, ok := new(big.Rat).SetString(strings.ReplaceAll(number, "_", ""))
+	if ok == false {
+		return 0, errByteSizeSyntax
+	}
+	value.Mul(value, new(big.Rat).SetUint64(unit.size))
+	if value.IsInt() == false {
+		return 0, errByteSizeSyntax
+	}
+	if value.Num().IsUint64() == false {
+		return 0, strconv.ErrRange
+	}
+	return ByteSize(value.Num().Uint64()), nil
+}
+
+func lookupByteUnit(name string) (byteUnit, bool) {
+	if name == "" {
+		return byteUnits[0], true
+	}
+	for _, unit := range byteUnits {
+		if strings.EqualFold(name, unit.name) == true || strings.EqualFold(name, strings.TrimSuffix(unit.name, "B")) == true {
+			return unit, true
+		}
+	}
+	return byteUnit{}, false
+}
+
+/*	Запись размера с наибольшей единицей, в которой он выражается целым числом  */
+func (this ByteSize) String() string {
+	if this == 0 {
+		return "0B"
+	}
+	best := byteUnits[0]
+	for _, unit := range byteUnits {
+		if uint64(this)%unit.size == 0 && unit.size > best.size {
+			best = unit
+		}
+	}
+	return fmt.Sprintf("%d%s", uint64(this)/best.size, best.name)
+}
+
+/*	Поле заполняется размером в байтах: тип ByteSize или целочисленное поле с тэгом unit:"bytes"  */
+func isByteSizeField(ftype reflect.Type, ftag reflect.StructTag) bool {
+	return ftype == byteSizeType || (ftag.Get("unit") == "bytes" && isIntegerKind(ftype.Kind()) == true)
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	return isCountableKind(kind) == true && isFloatKind(kind) == false
+}
+
+/*	Разбор размера из тэга min, max или enum в границу для сравнения  */
+func parseByteSizeTag(tag string) (int64, error) {
+	size, err := ParseByteSize(tag)
+	if err != nil {
+		return 0, err
+	}
+	if uint64(size) > math.MaxInt64 {
+		return 0, strconv.ErrRange
+	}
+	return int64(size), nil
+}
+
+/*	Заполнение размера в байтах. Возвращает false, если поле не является размером  */
+func (this *Configurator) decodeByteSize(ctx *parseContext, field reflect.Value, node *yaml.Node, ftype reflect.Type, ftag reflect.StructTag, path string) (bool, error) {
+	if isByteSizeField(ftype, ftag) == false {
+		return false, nil
+	}
+	value, err := nodeValue(node)
+	if err != nil {
+		return true, this.fieldError(ctx, err, path, node)
+	}
+	var size uint64
+	switch typedValue := value.(type) {
+	case string:
+		parsed, err := ParseByteSize(typedValue)
+		if errors.Is(err, strconv.ErrRange) == true {
+			return true, this.fieldError(ctx, rangeError(ftype, value, msgOverflow), path, node)
+		} else if errors.Is(err, errByteSizeNegative) == true {
+			return true, this.fieldError(ctx, rangeError(ftype, value, msgNegativeByteSize), path, node)
+		} else if err != nil {
+			return true, this.fieldError(ctx, &FieldError{
+				Kind:     KindType,
+				Expected: ftype.String(),
+				Got:      typedValue,
+				message:  msgByteSizeFormat,
+				args:     []interface{}{typedValue},
+			}, path, node)
+		}
+		size = uint64(parsed)
+	case int:
+		if typedValue < 0 {
+			return true, this.fieldError(ctx, rangeError(ftype, value, msgNegativeByteSize), path, node)
+		}
+		size = uint64(typedValue)
+	case uint64:
+		size = typedValue
+	default:
+		return true, this.fieldError(ctx, typeError(ftype, fmt.Sprintf("%T", value)), path, node)
+	}
+	result := reflect.New(ftype).Elem()
+	switch {
+	case result.CanUint() == true && result.OverflowUint(size) == false:
+		result.SetUint(size)
+	case result.CanInt() == true && size <= math.MaxInt64 && result.OverflowInt(int64(size)) == false:
+		result.SetInt(int64(size))
+	default:
+		return true, this.fieldError(ctx, rangeError(ftype, value, msgOverflow), path, node)
+	}
+	field.Set(result)
+	return true, nil
+}
@@ -0,0 +1,151 @@
+package yaml
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestByteSize(t *testing.T) {
+	t.Run("parse", func(t *testing.T) {
+		for text, expected := range map[string]ByteSize{
+			"0":         0,
+			"1024":      1024,
+			"1_000B":    1000,
+			"10GB":      10000000000,
+			"10gb":      10000000000,
+			"512MiB":    512 << 20,
+			"512Mi":     512 << 20,
+			"1.5 GiB":   3 << 29,
+			"64k":       64000,
+			"2KiB":      2048,
+			"16EiB":     0,
+			"0.5B":      0,
+			"-1MiB":     0,
+			"-0":        0,
+			"--1MiB":    0,
+			"MiB":       0,
+			"10 parsec": 0,
+		} {
+			size, err := ParseByteSize(text)
+			switch {
+			case text == "16EiB":
+				if errors.Is(err, strconv.ErrRange) == false {
+					t.Errorf("Fail: %s expected range error got %v", text, err)
+				}
+			case text == "-1MiB":
+				if errors.Is(err, errByteSizeNegative) == false {
+					t.Errorf("Fail: %s expected negative size error got %v", text, err)
+				}
+			case expected == 0 && text != "0" && text != "-0":
+				if err == nil {
+					t.Errorf("Fail: %s expected error got %d", text, size)
+				}
+			case err != nil || size != expected:
+				t.Errorf("Fail: %s expected %d got %d (%v)", text, expected, size, err)
+			}
+		}
+	})
+
+	t.Run("string", func(t *testing.T) {
+		for size, expected := range map[ByteSize]string{
+			0:              "0B",
+			1023:           "1023B",
+			512 << 20:      "512MiB",
+			10000000000:    "10GB",
+			1000000:        "1MB",
+			3 << 29:        "1536MiB",
+			1<<40 + 1<<20:  "1048577MiB",
+			1000 * 1 << 10: "1000KiB",
+		} {
+			if size.String() != expected {
+				t.Errorf("Fail: %d expected %s got %s", uint64(size), expected, size)
+			}
+		}
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		config := NewConfigurator(WithStrictTypes())
+		if err := config.ReadBytes([]byte(`
+            Cache:
+                Memory: 512MiB
+                Disk: 10GB
+                Buffer: 65536
+                Chunks: [4KiB, 1MB]
+                Limit: 1.5 GiB
+                Frame: 64KiB
+            Broken:
+                Memory: 2GiB
+                Disk: ten gigabytes
+                Buffer: -1
+                Frame: 8GiB
+                Limit: 1 byte
+                Chunks: [-5MiB]
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type CacheType struct {
+			Memory ByteSize   `conf:"Memory" max:"1GiB"`
+			Disk   *ByteSize  `conf:"Disk" min:"1GB"`
+			Buffer ByteSize   `conf:"Buffer"`
+			Chunks []ByteSize `conf:"Chunks"`
+			Limit  int64      `conf:"Limit" unit:"bytes" enum:"1GiB;1.5GiB"`
+			Frame  uint32     `conf:"Frame" unit:"bytes"`
+			Spill  ByteSize   `conf:"Spill" default:"256MiB"`
+			Blocks []int      `conf:"Blocks" unit:"bytes" default:"4KiB;8KiB"`
+		}
+		var dto CacheType
+		if err := config.ParseToStruct(&dto, "Cache"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.Memory != 512<<20 || dto.Disk == nil || *dto.Disk != 10000000000 || dto.Buffer != 65536 {
+			t.Errorf("Fail: unexpected sizes %#v", dto)
+		}
+		if len(dto.Chunks) != 2 || dto.Chunks[0] != 4096 || dto.Chunks[1] != 1000000 {
+			t.Errorf("Fail: unexpected Chunks %v", dto.Chunks)
+		}
+		if dto.Limit != 3<<29 || dto.Frame != 65536 || dto.Spill != 256<<20 {
+			t.Errorf("Fail: unexpected sizes %#v", dto)
+		}
+		if len(dto.Blocks) != 2 || dto.Blocks[1] != 8192 {
+			t.Errorf("Fail: unexpected Blocks %v", dto.Blocks)
+		}
+
+		err := config.ParseToStruct(&CacheType{}, "Broken")
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false || len(parseErrors) != 6 {
+			t.Errorf("Fail: unexpected error %v", err)
+			t.FailNow()
+		}
+		for i, expected := range []error{ErrMax, ErrType, ErrRange, ErrRange, ErrType, ErrRange} {
+			if errors.Is(parseErrors[i], expected) == false {
+				t.Errorf("Fail: expected %v got %s", expected, parseErrors[i])
+			}
+		}
+		t.Logf("Success. %s", err)
+	})
+
+	t.Run("tag errors", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadBytes([]byte("Alias:\n    Size: 1KiB\n")); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type UnknownUnitType struct {
+			Size int `conf:"Size" unit:"bits"`
+		}
+		type NotIntegerType struct {
+			Size float64 `conf:"Size" unit:"bytes"`
+		}
+		type BadBoundType struct {
+			Size ByteSize `conf:"Size" max:"a lot"`
+		}
+		for _, dto := range []interface{}{&UnknownUnitType{}, &NotIntegerType{}, &BadBoundType{}} {
+			if err := config.ParseToStruct(dto, "Alias"); errors.Is(err, ErrTag) == false {
+				t.Errorf("Fail: %T unexpected error %v", dto, err)
+			}
+		}
+	})
+}
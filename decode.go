@@ -0,0 +1,157 @@
+package yaml
+
+import (
+	"encoding"
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+	"reflect"
+)
+
+/*	Тип, самостоятельно заполняющий себя из конфигурационника (уровни логирования,
+**	ограничения скорости и т.д.). raw - значение в том виде, который выдает yaml декодер:
+**	int, float64, bool, string, nil, []interface{} или map[string]interface{}.
+**	Метод может быть объявлен как для значения, так и для указателя  */
+type Unmarshaler interface {
+	UnmarshalConf(raw interface{}) error
+}
+
+/*	Преобразование значения из конфигурационника (в том виде, который выдает yaml декодер)
+**	в значение зарегистрированного типа. Результат должен иметь этот тип или тип того же
+**	вида, приводимый к нему. nil означает нулевое значение типа  */
+type DecoderFunc func(raw interface{}) (interface{}, error)
+
+/*	Регистрирует декодер для типа, которым Configurator не владеет. Декодер применяется
+**	к полям структур, элементам срезов и словарей, значениям за указателями и имеет
+**	приоритет над встроенными преобразованиями (в том числе time.Duration) и методами
+**	UnmarshalConf, UnmarshalText и UnmarshalJSON. nil вместо декодера отменяет регистрацию  */
+func (this *Configurator) RegisterDecoder(rtype reflect.Type, decoder DecoderFunc) {
+	if decoder == nil {
+		this.decoders.Delete(rtype)
+		return
+	}
+	this.decoders.Store(rtype, decoder)
+}
+
+func (this *Configurator) decoderOf(ftype reflect.Type) DecoderFunc {
+	if decoder, exists := this.decoders.Load(ftype); exists == true {
+		return decoder.(DecoderFunc)
+	}
+	return nil
+}
+
+/*	Есть ли декодер для типа или для значения, на которое указывает указатель  */
+func (this *Configurator) hasDecoder(ftype reflect.Type) bool {
+	for {
+		if this.decoderOf(ftype) != nil {
+			return true
+		}
+		if ftype.Kind() != reflect.Ptr {
+			return false
+		}
+		ftype = ftype.Elem()
+	}
+}
+
+/*	Заполнение значения зарегистрированным декодером  */
+func (this *Configurator) decodeRegistered(ctx *parseContext, decoder DecoderFunc, field reflect.Value, node *yaml.Node, ftype reflect.Type, path string) error {
+	value, err := nodeValue(node)
+	if err != nil {
+		return this.fieldError(ctx, err, path, node)
+	}
+	result, err := decoder(value)
+	if err != nil {
+		return this.fieldError(ctx, &FieldError{
+			Kind:     KindType,
+			Expected: ftype.String(),
+			Got:      nodeValueType(node),
+			Err:      err,
+			message:  msgDecoderFailed,
+			args:     []interface{}{ftype.String()},
+		}, path, node)
+	}
+	val := reflect.ValueOf(result)
+	switch {
+	case val.IsValid() == false:
+		field.Set(reflect.Zero(ftype))
+	case val.Type().AssignableTo(ftype) == true:
+		field.Set(val)
+	/*	Приведение только внутри одного вида, чтобы int не превращался в строку из одной руны  */
+	case val.Kind() == ftype.Kind() && val.Type().ConvertibleTo(ftype) == true:
+		field.Set(val.Convert(ftype))
+	default:
+		return this.fieldError(ctx, &FieldError{
+			Kind:     KindType,
+			Expected: ftype.String(),
+			Got:      val.Type().String(),
+			message:  msgDecoderResultType,
+			args:     []interface{}{val.Type().String(), ftype.String()},
+		}, path, node)
+	}
+	return nil
+}
+
+var (
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+/*	Тип заполняет себя сам одним из методов UnmarshalConf, UnmarshalText или UnmarshalJSON  */
+func isSelfDecoding(ftype reflect.Type) bool {
+	pointer := reflect.PtrTo(ftype)
+	return pointer.Implements(unmarshalerType) == true ||
+		pointer.Implements(textUnmarshalerType) == true ||
+		pointer.Implements(jsonUnmarshalerType) == true
+}
+
+/*	Заполнение значения его собственным методом. Приоритет: Unmarshaler, затем
+**	encoding.TextUnmarshaler, затем json.Unmarshaler. Возвращает false, если тип
+**	не реализует ни один из интерфейсов. Для указателей также возвращается false:
+**	switchSetType сначала создает значение, на которое они ссылаются  */
+func (this *Configurator) decodeSelf(ctx *parseContext, field reflect.Value, node *yaml.Node, ftype reflect.Type, path string) (bool, error) {
+	if field.Kind() == reflect.Ptr || field.CanAddr() == false || field.Addr().CanInterface() == false {
+		return false, nil
+	}
+	var method string
+	var err error
+	switch target := field.Addr().Interface().(type) {
+	case Unmarshaler:
+		method = "UnmarshalConf"
+		var value interface{}
+		if value, err = nodeValue(node); err != nil {
+			return true, this.fieldError(ctx, err, path, node)
+		}
+		err = target.UnmarshalConf(value)
+	case encoding.TextUnmarshaler:
+		/*	Текст берется из yaml как есть и разбирается самим типом  */
+		if node.Kind != yaml.ScalarNode || isNullNode(node) == true {
+			return true, this.fieldError(ctx, typeError(ftype, nodeValueType(node)), path, node)
+		}
+		method = "UnmarshalText"
+		err = target.UnmarshalText([]byte(node.Value))
+	case json.Unmarshaler:
+		method = "UnmarshalJSON"
+		var value interface{}
+		if value, err = nodeValue(node); err != nil {
+			return true, this.fieldError(ctx, err, path, node)
+		}
+		var data []byte
+		if data, err = json.Marshal(value); err != nil {
+			return true, this.fieldError(ctx, typeError(ftype, nodeValueType(node)), path, node)
+		}
+		err = target.UnmarshalJSON(data)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return true, this.fieldError(ctx, &FieldError{
+			Kind:     KindType,
+			Expected: ftype.String(),
+			Got:      nodeValueType(node),
+			Err:      err,
+			message:  msgUnmarshalFailed,
+			args:     []interface{}{method, ftype.String()},
+		}, path, node)
+	}
+	return true, nil
+}
@@ -0,0 +1,333 @@
+package yaml
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota + 1
+	levelInfo
+	levelError
+)
+
+func (this *logLevel) UnmarshalConf(raw interface{}) error {
+	name, ok := raw.(string)
+	if ok == false {
+		return fmt.Errorf("log level must be a string, not %T", raw)
+	}
+	switch strings.ToLower(name) {
+	case "debug":
+		*this = levelDebug
+	case "info":
+		*this = levelInfo
+	case "error":
+		*this = levelError
+	default:
+		return fmt.Errorf("unknown log level %s", name)
+	}
+	return nil
+}
+
+/*	Ограничение скорости задается строкой вида 100/s или блоком с полями count и per  */
+type rateLimit struct {
+	Count int
+	Per   time.Duration
+}
+
+func (this *rateLimit) UnmarshalConf(raw interface{}) error {
+	switch typedValue := raw.(type) {
+	case string:
+		count, unit, found := strings.Cut(typedValue, "/")
+		if found == false {
+			return fmt.Errorf("rate limit %q has no unit", typedValue)
+		}
+		if _, err := fmt.Sscan(count, &this.Count); err != nil {
+			return err
+		}
+		per, err := time.ParseDuration("1" + unit)
+		if err != nil {
+			return err
+		}
+		this.Per = per
+	case map[string]interface{}:
+		count, _ := typedValue["count"].(int)
+		per, _ := typedValue["per"].(string)
+		duration, err := time.ParseDuration(per)
+		if err != nil {
+			return err
+		}
+		this.Count, this.Per = count, duration
+	default:
+		return fmt.Errorf("unsupported rate limit %T", raw)
+	}
+	return nil
+}
+
+/*	Тип с UnmarshalJSON, который должен получить значение блока в виде JSON  */
+type jsonEndpoint struct {
+	Host string
+	Port int
+}
+
+func (this *jsonEndpoint) UnmarshalJSON(data []byte) error {
+	var endpoint struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	if err := json.Unmarshal(data, &endpoint); err != nil {
+		return err
+	}
+	this.Host, this.Port = endpoint.Host, endpoint.Port
+	return nil
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("unmarshaler", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadBytes([]byte(`
+            Alias:
+                Level: INFO
+                Optional: error
+                Null: null
+                Levels: [debug, error]
+                ByModule:
+                    http: debug
+                Rate: 100/s
+                Burst:
+                    count: 5
+                    per: 1m
+            Broken:
+                Level: trace
+                Rate: 7
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			Level    logLevel            `conf:"Level"`
+			Optional *logLevel           `conf:"Optional"`
+			Null     *logLevel           `conf:"Null"`
+			Default  logLevel            `conf:"Default" default:"debug"`
+			Levels   []logLevel          `conf:"Levels"`
+			ByModule map[string]logLevel `conf:"ByModule"`
+			Rate     rateLimit           `conf:"Rate"`
+			Burst    *rateLimit          `conf:"Burst"`
+		}
+		var dto DtoType
+		if err := config.ParseToStruct(&dto, "Alias"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.Level != levelInfo || dto.Optional == nil || *dto.Optional != levelError || dto.Null != nil || dto.Default != levelDebug {
+			t.Errorf("Fail: unexpected levels %#v", dto)
+		}
+		if len(dto.Levels) != 2 || dto.Levels[1] != levelError || dto.ByModule["http"] != levelDebug {
+			t.Errorf("Fail: unexpected collections %#v %#v", dto.Levels, dto.ByModule)
+		}
+		if dto.Rate != (rateLimit{Count: 100, Per: time.Second}) || dto.Burst == nil || *dto.Burst != (rateLimit{Count: 5, Per: time.Minute}) {
+			t.Errorf("Fail: unexpected rates %#v %#v", dto.Rate, dto.Burst)
+		}
+
+		level, err := Parse[logLevel](config, "Alias.Optional")
+		if err != nil || level != levelError {
+			t.Errorf("Fail: unexpected level %v (%v)", level, err)
+		}
+
+		type BrokenType struct {
+			Level logLevel  `conf:"Level"`
+			Rate  rateLimit `conf:"Rate"`
+		}
+		err = config.ParseToStruct(&BrokenType{}, "Broken")
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false || len(parseErrors) != 2 || errors.Is(err, ErrType) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		} else if strings.Contains(err.Error(), "unknown log level trace") == false || strings.Contains(err.Error(), "unsupported rate limit int") == false {
+			t.Errorf("Fail: we expected another error %s", err)
+		} else {
+			t.Logf("Success. %s", err)
+		}
+	})
+
+	t.Run("text and json unmarshalers", func(t *testing.T) {
+		config := NewConfigurator(WithStrictTypes())
+		if err := config.ReadBytes([]byte(`
+            Alias:
+                IP: 10.0.0.1
+                Addr: "::1"
+                Allowed: [10.0.0.2, 10.0.0.3]
+                Gateways:
+                    eu: 192.168.0.1
+                Supply: 123456789012345678901234567890
+                Hex: 0x1F
+                Endpoint:
+                    host: localhost
+                    port: 8080
+            Broken:
+                IP: 10.0.0.256
+                Addr: [1, 2]
+                Endpoint: text
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			IP       net.IP                `conf:"IP"`
+			Addr     netip.Addr            `conf:"Addr"`
+			Allowed  []netip.Addr          `conf:"Allowed"`
+			Gateways map[string]netip.Addr `conf:"Gateways"`
+			Supply   *big.Int              `conf:"Supply"`
+			Hex      big.Int               `conf:"Hex"`
+			Endpoint jsonEndpoint          `conf:"Endpoint"`
+			Listen   netip.AddrPort        `conf:"Listen" default:"0.0.0.0:80"`
+		}
+		var dto DtoType
+		if err := config.ParseToStruct(&dto, "Alias"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.IP.Equal(net.ParseIP("10.0.0.1")) == false || dto.Addr != netip.MustParseAddr("::1") {
+			t.Errorf("Fail: unexpected addresses %v %v", dto.IP, dto.Addr)
+		}
+		if len(dto.Allowed) != 2 || dto.Allowed[1] != netip.MustParseAddr("10.0.0.3") || dto.Gateways["eu"] != netip.MustParseAddr("192.168.0.1") {
+			t.Errorf("Fail: unexpected collections %v %v", dto.Allowed, dto.Gateways)
+		}
+		if dto.Supply == nil || dto.Supply.String() != "123456789012345678901234567890" || dto.Hex.String() != "31" {
+			t.Errorf("Fail: unexpected numbers %v %v", dto.Supply, &dto.Hex)
+		}
+		if dto.Endpoint != (jsonEndpoint{Host: "localhost", Port: 8080}) || dto.Listen != netip.MustParseAddrPort("0.0.0.0:80") {
+			t.Errorf("Fail: unexpected endpoints %#v %v", dto.Endpoint, dto.Listen)
+		}
+
+		type BrokenType struct {
+			IP       net.IP       `conf:"IP"`
+			Addr     netip.Addr   `conf:"Addr"`
+			Endpoint jsonEndpoint `conf:"Endpoint"`
+		}
+		err := config.ParseToStruct(&BrokenType{}, "Broken")
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false || len(parseErrors) != 3 || errors.Is(err, ErrType) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		} else if strings.Contains(err.Error(), "UnmarshalText") == false || strings.Contains(err.Error(), "UnmarshalJSON") == false {
+			t.Errorf("Fail: we expected another error %s", err)
+		} else {
+			t.Logf("Success. %s", err)
+		}
+	})
+
+	t.Run("registered decoders", func(t *testing.T) {
+		source := []byte(`
+            Alias:
+                Timeout: 30
+                Endpoint: https://example.com/api
+                Mirrors: [https://a.example.com, https://b.example.com]
+                ByRegion:
+                    eu: https://eu.example.com
+                Backup: https://backup.example.com
+            Broken:
+                Endpoint: "://"
+                Timeout: [1]
+        `)
+		type DtoType struct {
+			Timeout  time.Duration      `conf:"Timeout"`
+			Endpoint url.URL            `conf:"Endpoint"`
+			Mirrors  []url.URL          `conf:"Mirrors"`
+			ByRegion map[string]url.URL `conf:"ByRegion"`
+			Backup   *url.URL           `conf:"Backup"`
+			Fallback *url.URL           `conf:"Fallback" default:"https://fallback.example.com"`
+			Retry    time.Duration      `conf:"Retry" default:"2"`
+		}
+		config := NewConfigurator()
+		if err := config.ReadBytes(source); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		/*	Длительность задается целым числом секунд. Значение тэга default приходит строкой  */
+		config.RegisterDecoder(reflect.TypeOf(time.Duration(0)), func(raw interface{}) (interface{}, error) {
+			switch typedValue := raw.(type) {
+			case int:
+				return time.Duration(typedValue) * time.Second, nil
+			case string:
+				seconds, err := strconv.Atoi(typedValue)
+				return time.Duration(seconds) * time.Second, err
+			default:
+				return nil, fmt.Errorf("duration must be a number of seconds, not %T", raw)
+			}
+		})
+		config.RegisterDecoder(reflect.TypeOf(url.URL{}), func(raw interface{}) (interface{}, error) {
+			text, _ := raw.(string)
+			parsed, err := url.Parse(text)
+			if err != nil {
+				return nil, err
+			}
+			return *parsed, nil
+		})
+		var dto DtoType
+		if err := config.ParseToStruct(&dto, "Alias"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.Timeout != 30*time.Second || dto.Retry != 2*time.Second {
+			t.Errorf("Fail: unexpected durations %v %v", dto.Timeout, dto.Retry)
+		}
+		if dto.Endpoint.Host != "example.com" || dto.Endpoint.Path != "/api" {
+			t.Errorf("Fail: unexpected endpoint %v", dto.Endpoint)
+		}
+		if len(dto.Mirrors) != 2 || dto.Mirrors[1].Host != "b.example.com" || dto.ByRegion["eu"].Host != "eu.example.com" {
+			t.Errorf("Fail: unexpected collections %v %v", dto.Mirrors, dto.ByRegion)
+		}
+		if dto.Backup == nil || dto.Backup.Host != "backup.example.com" || dto.Fallback == nil || dto.Fallback.Host != "fallback.example.com" {
+			t.Errorf("Fail: unexpected pointers %v %v", dto.Backup, dto.Fallback)
+		}
+
+		type BrokenType struct {
+			Endpoint url.URL       `conf:"Endpoint"`
+			Timeout  time.Duration `conf:"Timeout"`
+		}
+		err := config.ParseToStruct(&BrokenType{}, "Broken")
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false || len(parseErrors) != 2 || errors.Is(err, ErrType) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		} else {
+			t.Logf("Success. %s", err)
+		}
+
+		/*	Декодер, вернувший значение другого типа  */
+		config.RegisterDecoder(reflect.TypeOf(url.URL{}), func(raw interface{}) (interface{}, error) {
+			return raw, nil
+		})
+		if err := config.ParseToStruct(&BrokenType{}, "Alias"); errors.Is(err, ErrType) == false || strings.Contains(err.Error(), "string") == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+
+		/*	Декодеры принадлежат конкретному Configurator, а после отмены регистрации
+		**	снова работают встроенные преобразования  */
+		config.RegisterDecoder(reflect.TypeOf(time.Duration(0)), nil)
+		other := NewConfigurator()
+		if err := other.ReadBytes(source); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		for _, configurator := range []*Configurator{config, other} {
+			var timeout time.Duration
+			if err := configurator.ParseValue(&timeout, "Alias.Timeout"); err != nil || timeout != 30 {
+				t.Errorf("Fail: unexpected timeout %v (%v)", timeout, err)
+			}
+		}
+		if err := other.ParseToStruct(&DtoType{}, "Alias"); errors.Is(err, ErrType) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+	})
+}
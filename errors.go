@@ -0,0 +1,195 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"strings"
+)
+
+/*	Вид ошибки заполнения поля  */
+type ErrorKind string
+
+const (
+	KindMissing ErrorKind = "missing" // значение отсутствует в конфигурационнике
+	KindType    ErrorKind = "type"    // значение невозможно привести к типу поля
+	KindMin     ErrorKind = "min"     // значение меньше тэга min
+	KindMax     ErrorKind = "max"     // значение больше тэга max
+	KindEnum    ErrorKind = "enum"    // значение не входит в тэг enum
+	KindEnv     ErrorKind = "env"     // переменная окружения не найдена
+	KindTag     ErrorKind = "tag"     // некорректные тэги заполняемой структуры
+	KindRange   ErrorKind = "range"   // значение не помещается в диапазон типа поля
+)
+
+/*	Ошибки-признаки для проверки вида ошибки через errors.Is  */
+var (
+	ErrMissing = errors.New("значение не задано")
+	ErrType    = errors.New("несоответствие типа")
+	ErrMin     = errors.New("значение меньше минимального")
+	ErrMax     = errors.New("значение больше максимального")
+	ErrEnum    = errors.New("значение не входит в перечисление")
+	ErrEnv     = errors.New("переменная окружения не найдена")
+	ErrTag     = errors.New("некорректный тэг структуры")
+	ErrRange   = errors.New("значение вне диапазона типа")
+)
+
+var kindErrors = map[ErrorKind]error{
+	KindMissing: ErrMissing,
+	KindType:    ErrType,
+	KindMin:     ErrMin,
+	KindMax:     ErrMax,
+	KindEnum:    ErrEnum,
+	KindEnv:     ErrEnv,
+	KindTag:     ErrTag,
+	KindRange:   ErrRange,
+}
+
+/*	Сообщения по умолчанию для ошибок без собственного сообщения  */
+var kindMessages = map[ErrorKind]messageID{
+	KindMissing: msgKindMissing,
+	KindType:    msgKindType,
+	KindMin:     msgKindMin,
+	KindMax:     msgKindMax,
+	KindEnum:    msgKindEnum,
+	KindEnv:     msgKindEnv,
+	KindTag:     msgKindTag,
+	KindRange:   msgKindRange,
+}
+
+/*	Ошибка заполнения конкретного поля  */
+type FieldError struct {
+	Alias    string    // алиас блока конфигурации
+	Path     string    // путь к полю внутри алиаса, например Replicas[2].Port
+	Kind     ErrorKind // вид ошибки
+	Expected string    // ожидаемое значение (тип поля, граница min/max, варианты enum)
+	Got      string    // полученное значение или его тип
+	Err      error     // исходная ошибка, если есть
+	File     string    // файл, в котором находится значение (пусто для ReadBytes и ReadReader)
+	Line     int       // строка значения (для отсутствующих полей - строка родительского блока)
+	Column   int       // столбец значения
+	message  messageID
+	args     []interface{}
+	lang     string
+}
+
+func (this *FieldError) Error() string {
+	id := this.message
+	if id == 0 {
+		id = kindMessages[this.Kind]
+	}
+	reason := fmt.Sprintf(message(this.lang, id), this.args...)
+	if this.Err != nil {
+		reason = fmt.Sprintf("%s (%s)", reason, this.Err)
+	}
+	if location := this.Location(); location != "" {
+		reason = location + ": " + reason
+	}
+	switch {
+	case this.Alias == "":
+		return reason
+	case this.Path == "":
+		return fmt.Sprintf(message(this.lang, msgAliasLocation), reason, this.Alias)
+	default:
+		return fmt.Sprintf(message(this.lang, msgFieldLocation), reason, this.Path, this.Alias)
+	}
+}
+
+/*	Позиция значения в формате file:line:column (или line:column если файл неизвестен)  */
+func (this *FieldError) Location() string {
+	switch {
+	case this.Line == 0:
+		return ""
+	case this.File == "":
+		return fmt.Sprintf("%d:%d", this.Line, this.Column)
+	default:
+		return fmt.Sprintf("%s:%d:%d", this.File, this.Line, this.Column)
+	}
+}
+
+func (this *FieldError) Unwrap() error {
+	return this.Err
+}
+
+func (this *FieldError) Is(target error) bool {
+	return target != nil && kindErrors[this.Kind] == target
+}
+
+/*	Список ошибок заполнения структуры. Совместим с errors.Is и errors.As
+**	(метод Unwrap() []error), каждая ошибка содержит полный путь к полю  */
+type ParseErrors []error
+
+func (this ParseErrors) Error() string {
+	messages := make([]string, len(this))
+	for i, err := range this {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (this ParseErrors) Unwrap() []error {
+	return this
+}
+
+/*	Добавление ошибки в список. Вложенные списки разворачиваются  */
+func (this ParseErrors) append(err error) ParseErrors {
+	if nested, ok := err.(ParseErrors); ok == true {
+		return append(this, nested...)
+	}
+	return append(this, err)
+}
+
+/*	Дополнение ошибки путем к полю, именем алиаса и позицией узла в исходном файле  */
+func (this *Configurator) fieldError(ctx *parseContext, err error, path string, node *yaml.Node) error {
+	fieldErr, ok := err.(*FieldError)
+	if ok == false {
+		/*	Ошибка декодера yaml или другая ошибка без сообщения из каталога  */
+		fieldErr = &FieldError{Kind: KindType, Err: err, message: msgDecodeFailed}
+	}
+	fieldErr.Alias = ctx.alias
+	fieldErr.Path = path
+	fieldErr.lang = this.lang
+	if node != nil {
+		position := ctx.doc.position(node)
+		fieldErr.File = position.file
+		fieldErr.Line = position.line
+		fieldErr.Column = position.column
+	}
+	return fieldErr
+}
+
+/*	Ошибка описания тэгов заполняемой структуры  */
+func tagError(cause error, id messageID, args ...interface{}) *FieldError {
+	return &FieldError{
+		Kind:    KindTag,
+		Err:     cause,
+		message: id,
+		args:    args,
+	}
+}
+
+/*	Нарушение границы заданной тэгом min или max  */
+func boundError(kind ErrorKind, value interface{}, bound string) *FieldError {
+	id := msgMinValue
+	if kind == KindMax {
+		id = msgMaxValue
+	}
+	return &FieldError{
+		Kind:     kind,
+		Expected: bound,
+		Got:      fmt.Sprint(value),
+		message:  id,
+		args:     []interface{}{value, bound},
+	}
+}
+
+/*	Значение из конфигурационника не помещается в диапазон типа поля  */
+func rangeError(ftype reflect.Type, value interface{}, id messageID) *FieldError {
+	return &FieldError{
+		Kind:     KindRange,
+		Expected: ftype.String(),
+		Got:      fmt.Sprint(value),
+		message:  id,
+		args:     []interface{}{value, ftype.String()},
+	}
+}
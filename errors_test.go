@@ -0,0 +1,214 @@
+package yaml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseErrors(t *testing.T) {
+	source := []byte(`
+        Database:
+            Port: 0
+            Replicas:
+            - Host: replica1
+              Port: 5432
+            - Host: replica2
+              Port: not_a_number
+            Options:
+                Timeout: 5s
+    `)
+	type ReplicaType struct {
+		Host string `conf:"Host"`
+		Port int    `conf:"Port"`
+	}
+	type OptionsType struct {
+		Timeout string `conf:"Timeout"`
+		SslMode string `conf:"SslMode"`
+	}
+	type DatabaseType struct {
+		Host     string        `conf:"Host"`
+		Port     int           `conf:"Port" min:"1"`
+		Replicas []ReplicaType `conf:"Replicas"`
+		Options  OptionsType   `conf:"Options"`
+	}
+
+	t.Run("aggregate", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadBytes(source); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		var dto DatabaseType
+		err := config.ParseToStruct(&dto, "Database")
+		if err == nil {
+			t.Errorf("Fail: no error but it should be")
+			t.FailNow()
+		}
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false {
+			t.Errorf("Fail: error %T is not ParseErrors", err)
+			t.FailNow()
+		}
+		if len(parseErrors) != 4 {
+			t.Errorf("Fail: expected %d errors got %d: %s", 4, len(parseErrors), err)
+		}
+		for _, path := range []string{"поле Host,", "поле Port,", "поле Replicas[1].Port,", "поле Options.SslMode,"} {
+			if strings.Contains(err.Error(), path) == false {
+				t.Errorf("Fail: error does not contain %s: %s", path, err)
+			}
+		}
+		/*	Поля без ошибок все равно заполняются  */
+		if len(dto.Replicas) != 0 || dto.Options.Timeout != "5s" {
+			t.Errorf("Fail: unexpected dto %#v", dto)
+		}
+
+		joined := errors.Join(errors.New("other"), err)
+		if errors.As(joined, &parseErrors) == false {
+			t.Errorf("Fail: joined error does not contain ParseErrors")
+		}
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		config := NewConfigurator(WithFailFast())
+		if err := config.ReadBytes(source); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		var dto DatabaseType
+		err := config.ParseToStruct(&dto, "Database")
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false {
+			t.Errorf("Fail: error %T is not ParseErrors", err)
+			t.FailNow()
+		}
+		if len(parseErrors) != 1 {
+			t.Errorf("Fail: expected %d errors got %d: %s", 1, len(parseErrors), err)
+		}
+	})
+
+	t.Run("field errors", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadBytes(source); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		var dto DatabaseType
+		err := config.ParseToStruct(&dto, "Database")
+
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false {
+			t.Errorf("Fail: error %T is not ParseErrors", err)
+			t.FailNow()
+		}
+		expected := []FieldError{
+			{Alias: "Database", Path: "Host", Kind: KindMissing},
+			{Alias: "Database", Path: "Port", Kind: KindMin, Expected: "1", Got: "0"},
+			{Alias: "Database", Path: "Replicas[1].Port", Kind: KindType, Expected: "int", Got: "string"},
+			{Alias: "Database", Path: "Options.SslMode", Kind: KindMissing},
+		}
+		if len(parseErrors) != len(expected) {
+			t.Errorf("Fail: expected %d errors got %d: %s", len(expected), len(parseErrors), err)
+			t.FailNow()
+		}
+		for i, item := range parseErrors {
+			var fieldErr *FieldError
+			if errors.As(item, &fieldErr) == false {
+				t.Errorf("Fail: error %T is not *FieldError", item)
+				continue
+			}
+			if fieldErr.Alias != expected[i].Alias || fieldErr.Path != expected[i].Path || fieldErr.Kind != expected[i].Kind ||
+				fieldErr.Expected != expected[i].Expected || fieldErr.Got != expected[i].Got {
+				t.Errorf("Fail: expected %#v got %#v", expected[i], *fieldErr)
+			}
+		}
+
+		if errors.Is(err, ErrMissing) == false || errors.Is(err, ErrMin) == false || errors.Is(err, ErrType) == false {
+			t.Errorf("Fail: sentinel errors not found in %s", err)
+		}
+		if errors.Is(err, ErrEnum) == true {
+			t.Errorf("Fail: unexpected sentinel ErrEnum in %s", err)
+		}
+		if strings.Contains(parseErrors[1].Error(), " меньше значения ") == false {
+			t.Errorf("Fail: human readable message lost: %s", parseErrors[1])
+		}
+	})
+
+	t.Run("alias not found", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadBytes(source); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		err := config.ParseToStruct(&DatabaseType{}, "NotExist")
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) == false || fieldErr.Alias != "NotExist" || errors.Is(err, ErrMissing) == false {
+			t.Errorf("Fail: unexpected error %#v", err)
+		}
+	})
+
+	t.Run("tag and env errors", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadBytes([]byte("Alias:\n    Env: NOT_EXISTING_ENV_VARIABLE_42\n    Level: trace\n    Count: 1\n")); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			Env   string `conf:"Env" env:"true"`
+			Level string `conf:"Level" enum:"debug;info"`
+			Count string `conf:"Count" min:"1"`
+		}
+		err := config.ParseToStruct(&DtoType{}, "Alias")
+		if errors.Is(err, ErrEnv) == false || errors.Is(err, ErrEnum) == false || errors.Is(err, ErrTag) == false {
+			t.Errorf("Fail: sentinel errors not found in %s", err)
+		}
+	})
+
+	t.Run("positions", func(t *testing.T) {
+		dir := t.TempDir()
+		baseName := writeTestFile(t, dir, "base.yaml", "Database:\n    Host: db.local\n    Port: 5432\n")
+		prodName := writeTestFile(t, dir, "prod.yaml", "Service:\n    Name: billing\nDatabase:\n    Port: 70000\n")
+		config := NewConfigurator()
+		if err := config.ReadFiles(baseName, prodName); err != nil {
+			t.Errorf("Error while reading files: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			Host    string `conf:"Host"`
+			Port    int    `conf:"Port" max:"65535"`
+			SslMode string `conf:"SslMode"`
+		}
+		err := config.ParseToStruct(&DtoType{}, "Database")
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false || len(parseErrors) != 2 {
+			t.Errorf("Fail: unexpected error %v", err)
+			t.FailNow()
+		}
+		var fieldErr *FieldError
+		if errors.As(parseErrors[0], &fieldErr) == false || fieldErr.File != prodName || fieldErr.Line != 4 || fieldErr.Column != 11 {
+			t.Errorf("Fail: unexpected position of max error %#v", parseErrors[0])
+		}
+		if strings.HasPrefix(parseErrors[0].Error(), prodName+":4:11: ") == false {
+			t.Errorf("Fail: error does not start with position: %s", parseErrors[0])
+		}
+		if errors.As(parseErrors[1], &fieldErr) == false || fieldErr.File != baseName || fieldErr.Line != 1 || fieldErr.Column != 1 {
+			t.Errorf("Fail: missing field error does not point at alias block %#v", parseErrors[1])
+		}
+	})
+
+	t.Run("positions without file", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadBytes([]byte("Alias:\n    Count: many\n")); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			Count int `conf:"Count"`
+		}
+		err := config.ParseToStruct(&DtoType{}, "Alias")
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) == false || fieldErr.File != "" || fieldErr.Location() != "2:12" {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+	})
+}
@@ -0,0 +1,300 @@
+package yaml
+
+import (
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*	Ключ верхнего уровня, задающий включаемые файлы  */
+const includeKey = "$include"
+
+/*	Максимальная глубина вложенности $include по умолчанию  */
+const defaultIncludeDepth = 10
+
+/*	Загруженная конфигурация. После применения (commit) не изменяется: повторная загрузка
+**	создает новый документ, а узлы yaml при слиянии копируются, а не изменяются  */
+type document struct {
+	root      *yaml.Node
+	aliases   map[string]*yaml.Node
+	aliasKeys map[string]*yaml.Node
+	sources   map[string][]string
+	nodeFiles map[*yaml.Node]string
+	nodeKeys  map[*yaml.Node]*yaml.Node
+}
+
+/*	Накопитель источников. Изменения применяются к конфигуратору только
+**	после успешной загрузки всех источников (метод commit)  */
+type loader struct {
+	conf         *Configurator
+	aliases      map[string]*yaml.Node
+	aliasKeys    map[string]*yaml.Node
+	sources      map[string][]string
+	nodeFiles    map[*yaml.Node]string
+	readSource   func(fileName string) ([]byte, error)
+	resolvePath  func(baseFile, includeName string) string
+	includeStack []string
+}
+
+func (this *Configurator) newLoader(keepLoaded bool) *loader {
+	loader := &loader{
+		conf:        this,
+		aliases:     make(map[string]*yaml.Node),
+		aliasKeys:   make(map[string]*yaml.Node),
+		sources:     make(map[string][]string),
+		nodeFiles:   make(map[*yaml.Node]string),
+		readSource:  readFile,
+		resolvePath: resolveFilePath,
+	}
+	if keepLoaded == true {
+		doc := this.snapshot()
+		for aliasName, aliasNode := range doc.aliases {
+			loader.aliases[aliasName] = aliasNode
+		}
+		for aliasName, keyNode := range doc.aliasKeys {
+			loader.aliasKeys[aliasName] = keyNode
+		}
+		for aliasName, fileNames := range doc.sources {
+			loader.sources[aliasName] = fileNames
+		}
+		for node, fileName := range doc.nodeFiles {
+			loader.nodeFiles[node] = fileName
+		}
+	}
+	return loader
+}
+
+/*	Применение загруженных источников. Для каждого узла запоминается его ключ
+**	в родительском блоке, чтобы ошибки вложенных блоков указывали на позицию ключа  */
+func (this *Configurator) commit(loader *loader) {
+	nodeKeys := make(map[*yaml.Node]*yaml.Node)
+	aliasNames := make([]string, 0, len(loader.aliases))
+	for aliasName, aliasNode := range loader.aliases {
+		nodeKeys[aliasNode] = loader.aliasKeys[aliasName]
+		collectKeys(nodeKeys, aliasNode)
+		aliasNames = append(aliasNames, aliasName)
+	}
+	/*	Корневой блок документа из всех алиасов (для ParseAll)  */
+	sort.Strings(aliasNames)
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, aliasName := range aliasNames {
+		root.Content = append(root.Content, loader.aliasKeys[aliasName], loader.aliases[aliasName])
+	}
+	doc := &document{
+		root:      root,
+		aliases:   loader.aliases,
+		aliasKeys: loader.aliasKeys,
+		sources:   loader.sources,
+		nodeFiles: loader.nodeFiles,
+		nodeKeys:  nodeKeys,
+	}
+	this.mu.Lock()
+	this.doc = doc
+	this.mu.Unlock()
+}
+
+/*	Текущий снимок загруженных данных. Пустой документ, если ничего не загружено  */
+func (this *Configurator) snapshot() *document {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+	if this.doc == nil {
+		return &document{root: &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	return this.doc
+}
+
+/*	Переключение загрузчика на чтение из fs.FS. Пути включаемых файлов
+**	разрешаются внутри той же файловой системы  */
+func (this *loader) useFS(fsys fs.FS) {
+	this.readSource = func(fileName string) ([]byte, error) {
+		return fs.ReadFile(fsys, fileName)
+	}
+	this.resolvePath = func(baseFile, includeName string) string {
+		return path.Join(path.Dir(baseFile), includeName)
+	}
+}
+
+func resolveFilePath(baseFile, includeName string) string {
+	if filepath.IsAbs(includeName) == true {
+		return filepath.Clean(includeName)
+	}
+	return filepath.Join(filepath.Dir(baseFile), includeName)
+}
+
+func (this *loader) mergeFile(fileName string, depth int) error {
+	/*	Нормализация пути, чтобы a.yaml и ./a.yaml считались одним файлом при поиске циклов  */
+	fileName = this.resolvePath("", fileName)
+	for _, includedFile := range this.includeStack {
+		if includedFile == fileName {
+			return this.conf.errorf(msgIncludeCycle, strings.Join(this.includeStack, " -> "), fileName)
+		}
+	}
+	body, err := this.readSource(fileName)
+	if err != nil {
+		return err
+	}
+	this.includeStack = append(this.includeStack, fileName)
+	defer func() {
+		this.includeStack = this.includeStack[:len(this.includeStack)-1]
+	}()
+	return this.mergeBytes(fileName, body, depth)
+}
+
+/*	Слияние одного источника. Сначала сливаются включаемые файлы (в порядке перечисления),
+**	затем собственные алиасы источника, так что они перекрывают включенные  */
+func (this *loader) mergeBytes(fileName string, src []byte, depth int) error {
+	aliases, aliasKeys, includes, err := this.parseSource(fileName, src)
+	if err != nil {
+		if fileName != "" {
+			return this.conf.errorf(msgSourceFile, err, fileName)
+		}
+		return err
+	}
+	if len(includes) > 0 && depth >= this.conf.includeDepth() {
+		return this.conf.errorf(msgIncludeDepth, this.conf.includeDepth(), this.conf.sourceName(fileName))
+	}
+	for _, includeName := range includes {
+		if err := this.mergeFile(this.resolvePath(fileName, includeName), depth+1); err != nil {
+			return err
+		}
+	}
+	if this.conf.conflictPolicy == ConflictError {
+		for aliasName := range aliases {
+			if fileNames, exists := this.sources[aliasName]; exists == true {
+				return this.conf.errorf(msgAliasConflict, aliasName,
+					this.conf.sourceName(fileName), this.conf.sourceName(fileNames[len(fileNames)-1]))
+			}
+		}
+	}
+	for aliasName, aliasNode := range aliases {
+		if _, exists := this.aliasKeys[aliasName]; exists == false {
+			this.aliasKeys[aliasName] = aliasKeys[aliasName]
+		}
+		this.aliases[aliasName] = this.mergeAlias(this.aliases[aliasName], aliasNode)
+		this.sources[aliasName] = append(append([]string(nil), this.sources[aliasName]...), fileName)
+	}
+	return nil
+}
+
+func (this *Configurator) sourceName(fileName string) string {
+	if fileName == "" {
+		return message(this.lang, msgSourceUnnamed)
+	}
+	return fileName
+}
+
+/*	Разбор источника на значения ключей верхнего уровня (алиасов) и список включаемых файлов.
+**	Значением алиаса может быть блок, список или скалярное значение  */
+func (this *loader) parseSource(fileName string, src []byte) (map[string]*yaml.Node, map[string]*yaml.Node, []string, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(src, &document); err != nil {
+		return nil, nil, nil, err
+	}
+	aliases := make(map[string]*yaml.Node)
+	aliasKeys := make(map[string]*yaml.Node)
+	if len(document.Content) == 0 || isNullNode(document.Content[0]) {
+		return aliases, aliasKeys, nil, nil
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, nil, this.conf.errorf(msgRootNotBlock)
+	}
+	this.prepareNodes(root, fileName)
+
+	var includes []string
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Value == includeKey {
+			var rawIncludes interface{}
+			if err := value.Decode(&rawIncludes); err != nil {
+				return nil, nil, nil, err
+			}
+			parsed, err := this.conf.parseIncludes(rawIncludes)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			includes = append(includes, parsed...)
+			continue
+		}
+		aliases[key.Value] = value
+		aliasKeys[key.Value] = key
+	}
+	return aliases, aliasKeys, includes, nil
+}
+
+/*	Обход дерева источника: запоминание файла для каждого узла и отключение неявного
+**	распознавания дат (значения вида 2024-01-01 остаются строками, как в yaml.v2)  */
+func (this *loader) prepareNodes(node *yaml.Node, fileName string) {
+	this.nodeFiles[node] = fileName
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!timestamp" && node.Style&yaml.TaggedStyle == 0 {
+		node.Tag = "!!str"
+	}
+	for _, child := range node.Content {
+		this.prepareNodes(child, fileName)
+	}
+}
+
+/*	Позиция значения в исходном файле  */
+type position struct {
+	file   string
+	line   int
+	column int
+}
+
+func collectKeys(nodeKeys map[*yaml.Node]*yaml.Node, node *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			nodeKeys[node.Content[i+1]] = node.Content[i]
+			collectKeys(nodeKeys, node.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			collectKeys(nodeKeys, item)
+		}
+	}
+}
+
+/*	Позиция узла в исходном файле. Для блоков и пустых значений используется позиция ключа  */
+func (this *document) position(node *yaml.Node) position {
+	if key, exists := this.nodeKeys[node]; exists == true && (node.Kind != yaml.ScalarNode || isNullNode(node) == true) {
+		node = key
+	}
+	return position{file: this.nodeFiles[node], line: node.Line, column: node.Column}
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+func isNullNode(node *yaml.Node) bool {
+	node = resolveAlias(node)
+	return node == nil || (node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null")
+}
+
+func (this *Configurator) parseIncludes(value interface{}) ([]string, error) {
+	switch typedValue := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{typedValue}, nil
+	case []interface{}:
+		includes := make([]string, 0, len(typedValue))
+		for _, item := range typedValue {
+			includeName, ok := item.(string)
+			if ok == false {
+				return nil, this.errorf(msgIncludeItemType, includeKey, item)
+			}
+			includes = append(includes, includeName)
+		}
+		return includes, nil
+	default:
+		return nil, this.errorf(msgIncludeType, includeKey, value)
+	}
+}
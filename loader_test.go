@@ -0,0 +1,136 @@
+package yaml
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestInclude(t *testing.T) {
+	type DatabaseType struct {
+		Host string `conf:"Host"`
+		Port int    `conf:"Port"`
+	}
+	type ServiceType struct {
+		Name string `conf:"Name"`
+	}
+
+	t.Run("include relative to file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestFile(t, dir, "shared/database.yaml", `
+Database:
+    Host: db.shared
+    Port: 5432
+`)
+		fileName := writeTestFile(t, dir, "service/app.yaml", `
+$include: ../shared/database.yaml
+Service:
+    Name: billing
+Database:
+    Port: 6432
+`)
+		config := NewConfigurator()
+		if err := config.ReadFile(fileName); err != nil {
+			t.Errorf("Error while reading file: %s", err)
+			t.FailNow()
+		}
+		var database DatabaseType
+		if err := config.ParseToStruct(&database, "Database"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if database.Host != "db.shared" || database.Port != 6432 {
+			t.Errorf("Fail: unexpected dto %#v", database)
+		}
+		var service ServiceType
+		if err := config.ParseToStruct(&service, "Service"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+		}
+		sources := config.AliasSources("Database")
+		if len(sources) != 2 || filepath.Base(sources[0]) != "database.yaml" || filepath.Base(sources[1]) != "app.yaml" {
+			t.Errorf("Fail: unexpected sources %#v", sources)
+		}
+	})
+
+	t.Run("include list", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestFile(t, dir, "database.yaml", "Database:\n    Host: db.shared\n    Port: 5432\n")
+		writeTestFile(t, dir, "service.yaml", "Service:\n    Name: billing\n")
+		fileName := writeTestFile(t, dir, "app.yaml", "$include:\n- database.yaml\n- service.yaml\n")
+		config := NewConfigurator()
+		if err := config.ReadFile(fileName); err != nil {
+			t.Errorf("Error while reading file: %s", err)
+			t.FailNow()
+		}
+		var service ServiceType
+		if err := config.ParseToStruct(&service, "Service"); err != nil || service.Name != "billing" {
+			t.Errorf("Fail: unexpected result %#v %v", service, err)
+		}
+	})
+
+	t.Run("include cycle", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestFile(t, dir, "a.yaml", "$include: b.yaml\nA:\n    Name: a\n")
+		writeTestFile(t, dir, "b.yaml", "$include: ./a.yaml\nB:\n    Name: b\n")
+		config := NewConfigurator()
+		if err := config.ReadFile(filepath.Join(dir, "a.yaml")); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		} else if strings.Contains(err.Error(), "циклическое включение") == false {
+			t.Errorf("Fail: we expected another error %s", err)
+		} else {
+			t.Logf("Success. %s", err)
+		}
+	})
+
+	t.Run("include depth", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestFile(t, dir, "1.yaml", "$include: 2.yaml\n")
+		writeTestFile(t, dir, "2.yaml", "$include: 3.yaml\n")
+		writeTestFile(t, dir, "3.yaml", "Service:\n    Name: deep\n")
+
+		config := NewConfigurator(WithIncludeDepth(1))
+		if err := config.ReadFile(filepath.Join(dir, "1.yaml")); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		} else if strings.Contains(err.Error(), "глубина включения") == false {
+			t.Errorf("Fail: we expected another error %s", err)
+		}
+
+		config = NewConfigurator(WithIncludeDepth(2))
+		if err := config.ReadFile(filepath.Join(dir, "1.yaml")); err != nil {
+			t.Errorf("Error while reading file: %s", err)
+		}
+	})
+
+	t.Run("include invalid value", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadBytes([]byte("$include:\n    File: a.yaml\n")); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		}
+	})
+
+	t.Run("include missing file", func(t *testing.T) {
+		dir := t.TempDir()
+		fileName := writeTestFile(t, dir, "app.yaml", "$include: not_exist.yaml\n")
+		config := NewConfigurator()
+		if err := config.ReadFile(fileName); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		}
+	})
+
+	t.Run("include inside fs", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"conf/app.yaml":             &fstest.MapFile{Data: []byte("$include: shared/database.yaml\nService:\n    Name: billing\n")},
+			"conf/shared/database.yaml": &fstest.MapFile{Data: []byte("Database:\n    Host: db.fs\n    Port: 5432\n")},
+		}
+		config := NewConfigurator()
+		if err := config.ReadFS(fsys, "conf/app.yaml"); err != nil {
+			t.Errorf("Error while reading fs: %s", err)
+			t.FailNow()
+		}
+		var database DatabaseType
+		if err := config.ParseToStruct(&database, "Database"); err != nil || database.Host != "db.fs" {
+			t.Errorf("Fail: unexpected result %#v %v", database, err)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package yaml
+
+import (
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+/*	Чтение нескольких файлов с глубоким слиянием в порядке перечисления.
+**	Значения из более поздних файлов перекрывают значения из более ранних  */
+func (this *Configurator) ReadFiles(fileNames ...string) error {
+	this.loadMu.Lock()
+	defer this.loadMu.Unlock()
+	loader := this.newLoader(false)
+	for _, fileName := range fileNames {
+		if err := loader.mergeFile(fileName, 0); err != nil {
+			return err
+		}
+	}
+	this.commit(loader)
+	return nil
+}
+
+/*	Чтение всех файлов директории, подходящих под шаблон (например *.yaml),
+**	в лексическом порядке имен с последующим слиянием  */
+func (this *Configurator) ReadDir(dir, pattern string) error {
+	fileNames, err := globFiles(dir, pattern)
+	if err != nil {
+		return err
+	}
+	return this.ReadFiles(fileNames...)
+}
+
+/*	Слияние файла с уже загруженной конфигурацией  */
+func (this *Configurator) MergeFile(fileName string) error {
+	this.loadMu.Lock()
+	defer this.loadMu.Unlock()
+	loader := this.newLoader(true)
+	if err := loader.mergeFile(fileName, 0); err != nil {
+		return err
+	}
+	this.commit(loader)
+	return nil
+}
+
+/*	Слияние среза байт с уже загруженной конфигурацией  */
+func (this *Configurator) MergeBytes(src []byte) error {
+	this.loadMu.Lock()
+	defer this.loadMu.Unlock()
+	loader := this.newLoader(true)
+	if err := loader.mergeBytes("", src, 0); err != nil {
+		return err
+	}
+	this.commit(loader)
+	return nil
+}
+
+/*	Список файлов в которых был определен алиас (в порядке слияния)  */
+func (this *Configurator) AliasSources(aliasName string) []string {
+	return append([]string(nil), this.snapshot().sources[aliasName]...)
+}
+
+func globFiles(dir, pattern string) ([]string, error) {
+	if pattern == "" {
+		pattern = "*"
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	fileNames := make([]string, 0, len(matches))
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() == false {
+			fileNames = append(fileNames, match)
+		}
+	}
+	return fileNames, nil
+}
+
+/*	Слияние блоков алиаса. Пустой (null) блок из более позднего источника
+**	не затирает ранее загруженный  */
+func (this *loader) mergeAlias(dst, src *yaml.Node) *yaml.Node {
+	if dst == nil {
+		return src
+	}
+	if isNullNode(src) == true {
+		return dst
+	}
+	return this.mergeNode(dst, src)
+}
+
+/*	Рекурсивное слияние узлов. Вложенные словари сливаются по ключам,
+**	списки - в соответствии с выбранной стратегией, прочие значения перекрываются.
+**	Исходные узлы не изменяются  */
+func (this *loader) mergeNode(dst, src *yaml.Node) *yaml.Node {
+	dst, src = resolveAlias(dst), resolveAlias(src)
+	switch {
+	case dst == nil:
+		return src
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		result := *dst
+		result.Content = append([]*yaml.Node(nil), dst.Content...)
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key, value := src.Content[i], src.Content[i+1]
+			if j := mappingIndex(&result, key.Value); j >= 0 {
+				result.Content[j+1] = this.mergeNode(result.Content[j+1], value)
+			} else {
+				result.Content = append(result.Content, key, value)
+			}
+		}
+		this.nodeFiles[&result] = this.nodeFiles[dst]
+		return &result
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode && this.conf.listStrategy == ListAppend:
+		result := *dst
+		result.Content = make([]*yaml.Node, 0, len(dst.Content)+len(src.Content))
+		result.Content = append(append(result.Content, dst.Content...), src.Content...)
+		this.nodeFiles[&result] = this.nodeFiles[dst]
+		return &result
+	default:
+		return src
+	}
+}
+
+/*	Индекс ключа в содержимом узла-словаря или -1  */
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
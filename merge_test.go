@@ -0,0 +1,239 @@
+package yaml
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	fileName := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		t.Fatalf("Error while creating directory: %s", err)
+	}
+	if err := os.WriteFile(fileName, []byte(body), 0644); err != nil {
+		t.Fatalf("Error while writing file: %s", err)
+	}
+	return fileName
+}
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	base := writeTestFile(t, dir, "base.yaml", `
+Database:
+    Host: localhost
+    Port: 5432
+    Options:
+        SslMode: disable
+        Timeout: 5s
+    Replicas:
+    - replica1
+Logging:
+    Level: debug
+`)
+	prod := writeTestFile(t, dir, "prod.yaml", `
+Database:
+    Host: db.prod
+    Options:
+        SslMode: require
+    Replicas:
+    - replica2
+Metrics:
+    Port: 9090
+`)
+
+	type OptionsType struct {
+		SslMode string `conf:"SslMode"`
+		Timeout string `conf:"Timeout"`
+	}
+	type DatabaseType struct {
+		Host     string      `conf:"Host"`
+		Port     int         `conf:"Port"`
+		Options  OptionsType `conf:"Options"`
+		Replicas []string    `conf:"Replicas"`
+	}
+
+	t.Run("read files replace lists", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadFiles(base, prod); err != nil {
+			t.Errorf("Error while reading files: %s", err)
+			t.FailNow()
+		}
+		var dto DatabaseType
+		if err := config.ParseToStruct(&dto, "Database"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.Host != "db.prod" {
+			t.Errorf("Fail: field %s expected %s got %s", "dto.Host", "db.prod", dto.Host)
+		}
+		if dto.Port != 5432 {
+			t.Errorf("Fail: field %s expected %d got %d", "dto.Port", 5432, dto.Port)
+		}
+		if dto.Options.SslMode != "require" || dto.Options.Timeout != "5s" {
+			t.Errorf("Fail: field %s got %#v", "dto.Options", dto.Options)
+		}
+		if len(dto.Replicas) != 1 || dto.Replicas[0] != "replica2" {
+			t.Errorf("Fail: field %s got %#v", "dto.Replicas", dto.Replicas)
+		}
+
+		type LoggingType struct {
+			Level string `conf:"Level"`
+		}
+		var logging LoggingType
+		if err := config.ParseToStruct(&logging, "Logging"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+		}
+		type MetricsType struct {
+			Port int `conf:"Port"`
+		}
+		var metrics MetricsType
+		if err := config.ParseToStruct(&metrics, "Metrics"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+		}
+	})
+
+	t.Run("read files append lists", func(t *testing.T) {
+		config := NewConfigurator(WithListStrategy(ListAppend))
+		if err := config.ReadFiles(base, prod); err != nil {
+			t.Errorf("Error while reading files: %s", err)
+			t.FailNow()
+		}
+		var dto DatabaseType
+		if err := config.ParseToStruct(&dto, "Database"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if len(dto.Replicas) != 2 || dto.Replicas[0] != "replica1" || dto.Replicas[1] != "replica2" {
+			t.Errorf("Fail: field %s got %#v", "dto.Replicas", dto.Replicas)
+		}
+	})
+
+	t.Run("merge after read", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadFile(base); err != nil {
+			t.Errorf("Error while reading file: %s", err)
+			t.FailNow()
+		}
+		if err := config.MergeBytes([]byte("Database:\n    Port: 6432\n")); err != nil {
+			t.Errorf("Error while merging: %s", err)
+			t.FailNow()
+		}
+		var dto DatabaseType
+		if err := config.ParseToStruct(&dto, "Database"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.Host != "localhost" || dto.Port != 6432 {
+			t.Errorf("Fail: unexpected dto %#v", dto)
+		}
+	})
+
+	t.Run("read file resets merged data", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadFiles(base, prod); err != nil {
+			t.Errorf("Error while reading files: %s", err)
+			t.FailNow()
+		}
+		if err := config.ReadFile(base); err != nil {
+			t.Errorf("Error while reading file: %s", err)
+			t.FailNow()
+		}
+		type MetricsType struct {
+			Port int `conf:"Port"`
+		}
+		if err := config.ParseToStruct(&MetricsType{}, "Metrics"); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadFiles(base, filepath.Join(dir, "not_exist.yaml")); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		}
+	})
+
+	t.Run("read dir", func(t *testing.T) {
+		confDir := filepath.Join(dir, "conf.d")
+		writeTestFile(t, confDir, "20-prod.yaml", "Database:\n    Host: db.prod\n")
+		writeTestFile(t, confDir, "10-base.yaml", "Database:\n    Host: localhost\n    Port: 5432\n")
+		writeTestFile(t, confDir, "readme.txt", "not a yaml")
+		writeTestFile(t, confDir, "30-sub/ignored.yaml", "Database:\n    Host: ignored\n")
+
+		config := NewConfigurator()
+		if err := config.ReadDir(confDir, "*.yaml"); err != nil {
+			t.Errorf("Error while reading dir: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			Host string `conf:"Host"`
+			Port int    `conf:"Port"`
+		}
+		var dto DtoType
+		if err := config.ParseToStruct(&dto, "Database"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.Host != "db.prod" || dto.Port != 5432 {
+			t.Errorf("Fail: unexpected dto %#v", dto)
+		}
+
+		sources := config.AliasSources("Database")
+		if len(sources) != 2 || filepath.Base(sources[0]) != "10-base.yaml" || filepath.Base(sources[1]) != "20-prod.yaml" {
+			t.Errorf("Fail: unexpected sources %#v", sources)
+		}
+
+		config = NewConfigurator(WithConflictPolicy(ConflictError))
+		if err := config.ReadDir(confDir, "*.yaml"); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		} else if strings.Contains(err.Error(), "20-prod.yaml") == false || strings.Contains(err.Error(), "10-base.yaml") == false {
+			t.Errorf("Fail: we expected another error %s", err)
+		} else {
+			t.Logf("Success. %s", err)
+		}
+
+		if err := config.ReadDir(filepath.Join(dir, "not_exist.d"), "*.yaml"); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		}
+	})
+
+	t.Run("conflict policy error on merge", func(t *testing.T) {
+		config := NewConfigurator(WithConflictPolicy(ConflictError))
+		if err := config.ReadFile(base); err != nil {
+			t.Errorf("Error while reading file: %s", err)
+			t.FailNow()
+		}
+		if err := config.MergeFile(prod); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		}
+		if err := config.MergeBytes([]byte("Other:\n    Value: 1\n")); err != nil {
+			t.Errorf("Error while merging: %s", err)
+		}
+		if sources := config.AliasSources("Database"); len(sources) != 1 || sources[0] != base {
+			t.Errorf("Fail: unexpected sources %#v", sources)
+		}
+	})
+
+	t.Run("merge top level values", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadBytes([]byte("LogLevel: info\nHosts: [a, b]\n")); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		if err := config.MergeBytes([]byte("LogLevel: debug\nHosts: [c]\n")); err != nil {
+			t.Errorf("Error while merging: %s", err)
+			t.FailNow()
+		}
+		var logLevel string
+		if err := config.ParseValue(&logLevel, "LogLevel"); err != nil || logLevel != "debug" {
+			t.Errorf("Fail: LogLevel expected %s got %s (%v)", "debug", logLevel, err)
+		}
+		var hosts []string
+		if err := config.ParseValue(&hosts, "Hosts"); err != nil || len(hosts) != 1 || hosts[0] != "c" {
+			t.Errorf("Fail: unexpected Hosts %#v (%v)", hosts, err)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package yaml
+
+import "fmt"
+
+/*	Поддерживаемые языки сообщений об ошибках  */
+const (
+	LanguageRussian = "ru"
+	LanguageEnglish = "en"
+)
+
+/*	Идентификатор сообщения в каталоге. Нулевое значение - сообщение не задано  */
+type messageID int
+
+const (
+	msgAliasNotFound messageID = iota + 1
+	msgAliasLocation
+	msgFieldLocation
+	msgStructBodyType
+	msgMissingValue
+	msgTypeMismatch
+	msgMinNotCountable
+	msgMaxNotCountable
+	msgEnvNotTrue
+	msgEnvNotString
+	msgEnvValueNotString
+	msgEnvNotFound
+	msgEnumNotSupported
+	msgEnumParseInt
+	msgEnumParseFloat
+	msgEnumMismatch
+	msgMinParseInt
+	msgMinParseFloat
+	msgMinValue
+	msgMaxParseInt
+	msgMaxParseFloat
+	msgMaxValue
+	msgConfUnknownOption
+	msgConfConflictOptions
+	msgDefaultParse
+	msgDefaultUnsupported
+	msgSourceFile
+	msgSourceUnnamed
+	msgAliasConflict
+	msgIncludeCycle
+	msgIncludeDepth
+	msgIncludeItemType
+	msgIncludeType
+	msgRootNotBlock
+	msgTargetNotPointer
+	msgTargetNil
+	msgTargetNotStruct
+	msgOverflow
+	msgNegativeUnsigned
+	msgUnmarshalFailed
+	msgDecoderFailed
+	msgDecoderResultType
+	msgTimeLayout
+	msgUnknownLocation
+	msgByteSizeFormat
+	msgNegativeByteSize
+	msgUnitUnknown
+	msgUnitNotInteger
+	msgDecodeFailed
+	msgKindMissing
+	msgKindType
+	msgKindMin
+	msgKindMax
+	msgKindEnum
+	msgKindEnv
+	msgKindTag
+	msgKindRange
+)
+
+var catalogs = map[string]map[messageID]string{
+	LanguageRussian: {
+		msgAliasNotFound:       "Алиас отсутствует в конфигурационном файле",
+		msgAliasLocation:       "%s (алиас %s)",
+		msgFieldLocation:       "%s (поле %s, алиас %s)",
+		msgStructBodyType:      "Тело структуры невозможно заполнить так как попался необрабатываемый тип %s",
+		msgMissingValue:        "Не задано значение",
+		msgTypeMismatch:        "Невозможно установить значение с типом %s в поле с типом %s",
+		msgMinNotCountable:     "Поле имеет тэг min но при этом не является исчислимым",
+		msgMaxNotCountable:     "Поле имеет тэг max но при этом не является исчислимым",
+		msgEnvNotTrue:          "Поле имеет тэг env но при этом не установлено в true",
+		msgEnvNotString:        "Поле имеет тэг env но при этом не является строкой",
+		msgEnvValueNotString:   "Поле имеет тэг env но значение в конфигурационном файле не является строкой",
+		msgEnvNotFound:         "Поле имеет тэг env но переменная окружения %s не обнаружена в системе",
+		msgEnumNotSupported:    "Поле имеет тэг enum но при этом не является ни исчислимым ни строкой",
+		msgEnumParseInt:        "Не смог распарсить часть тэга enum (%s) структуры в целочисленный тип",
+		msgEnumParseFloat:      "Не смог распарсить часть тэга enum (%s) структуры в тип float",
+		msgEnumMismatch:        "Поле не соответствует ни одному из перечисленный в enum значений (%s)",
+		msgMinParseInt:         "Не смог распарсить тэг min структуры в целочисленный тип",
+		msgMinParseFloat:       "Не смог распарсить тэг min структуры в тип float",
+		msgMinValue:            "Значение поля в конфигурационном файле %v меньше значения %s заданного тэгом min заполняемой структуры",
+		msgMaxParseInt:         "Не смог распарсить тэг max структуры в целочисленный тип",
+		msgMaxParseFloat:       "Не смог распарсить тэг max структуры в тип float",
+		msgMaxValue:            "Значение поля в конфигурационном файле %v больше значения %s заданного тэгом max заполняемой структуры",
+		msgConfUnknownOption:   "Тэг conf содержит неизвестную опцию %s",
+		msgConfConflictOptions: "Тэг conf содержит взаимоисключающие опции required и optional/omitempty",
+		msgDefaultParse:        "Не смог распарсить тэг default (%s) в тип %s",
+		msgDefaultUnsupported:  "Тэг default не поддерживается для поля с типом %s",
+		msgSourceFile:          "%w (файл %s)",
+		msgSourceUnnamed:       "<без имени>",
+		msgAliasConflict:       "Алиас <%s> из источника %s уже определен в источнике %s",
+		msgIncludeCycle:        "Обнаружено циклическое включение файлов: %s -> %s",
+		msgIncludeDepth:        "Превышена максимальная глубина включения файлов %d (файл %s)",
+		msgIncludeItemType:     "Значение %s должно быть строкой или списком строк, а не содержать %T",
+		msgIncludeType:         "Значение %s должно быть строкой или списком строк, а не %T",
+		msgRootNotBlock:        "Конфигурационный файл должен содержать блок алиасов ключ-значение",
+		msgTargetNotPointer:    "Заполняемое значение должно передаваться указателем, а не значением типа %T",
+		msgTargetNil:           "Заполняемое значение не может быть нулевым указателем %T",
+		msgTargetNotStruct:     "Заполняемое значение должно быть указателем на структуру, а не %T",
+		msgOverflow:            "Значение %v не помещается в поле с типом %s",
+		msgNegativeUnsigned:    "Отрицательное значение %v невозможно установить в беззнаковое поле с типом %s",
+		msgUnmarshalFailed:     "Метод %s типа %s вернул ошибку",
+		msgDecoderFailed:       "Декодер, зарегистрированный для типа %s, вернул ошибку",
+		msgDecoderResultType:   "Декодер вернул значение с типом %s вместо %s",
+		msgTimeLayout:          "Значение %s не соответствует ни одному из форматов времени (%s)",
+		msgUnknownLocation:     "Неизвестный часовой пояс %s",
+		msgByteSizeFormat:      "Значение %s не является размером в байтах (например 512MiB или 10GB)",
+		msgNegativeByteSize:    "Отрицательный размер %v невозможно установить в поле с типом %s",
+		msgUnitUnknown:         "Тэг unit содержит неизвестную единицу измерения %s",
+		msgUnitNotInteger:      "Поле имеет тэг unit но при этом не является целочисленным (тип %s)",
+		msgDecodeFailed:        "Невозможно разобрать значение",
+		msgKindMissing:         "Значение не задано",
+		msgKindType:            "Несоответствие типа",
+		msgKindMin:             "Значение меньше минимального",
+		msgKindMax:             "Значение больше максимального",
+		msgKindEnum:            "Значение не входит в перечисление",
+		msgKindEnv:             "Переменная окружения не найдена",
+		msgKindTag:             "Некорректный тэг структуры",
+		msgKindRange:           "Значение вне диапазона типа",
+	},
+	LanguageEnglish: {
+		msgAliasNotFound:       "Alias is missing in the configuration file",
+		msgAliasLocation:       "%s (alias %s)",
+		msgFieldLocation:       "%s (field %s, alias %s)",
+		msgStructBodyType:      "Cannot fill the struct body because of unsupported value type %s",
+		msgMissingValue:        "Value is not set",
+		msgTypeMismatch:        "Cannot set value of type %s into field of type %s",
+		msgMinNotCountable:     "Field has min tag but is not numeric",
+		msgMaxNotCountable:     "Field has max tag but is not numeric",
+		msgEnvNotTrue:          "Field has env tag but it is not set to true",
+		msgEnvNotString:        "Field has env tag but is not a string",
+		msgEnvValueNotString:   "Field has env tag but the value in the configuration file is not a string",
+		msgEnvNotFound:         "Field has env tag but environment variable %s is not found",
+		msgEnumNotSupported:    "Field has enum tag but is neither numeric nor string",
+		msgEnumParseInt:        "Cannot parse enum tag item (%s) as integer",
+		msgEnumParseFloat:      "Cannot parse enum tag item (%s) as float",
+		msgEnumMismatch:        "Value does not match any of enum values (%s)",
+		msgMinParseInt:         "Cannot parse min tag as integer",
+		msgMinParseFloat:       "Cannot parse min tag as float",
+		msgMinValue:            "Value %v in the configuration file is less than %s set by min tag",
+		msgMaxParseInt:         "Cannot parse max tag as integer",
+		msgMaxParseFloat:       "Cannot parse max tag as float",
+		msgMaxValue:            "Value %v in the configuration file is greater than %s set by max tag",
+		msgConfUnknownOption:   "Conf tag contains unknown option %s",
+		msgConfConflictOptions: "Conf tag contains mutually exclusive options required and optional/omitempty",
+		msgDefaultParse:        "Cannot parse default tag (%s) as %s",
+		msgDefaultUnsupported:  "Default tag is not supported for field of type %s",
+		msgSourceFile:          "%w (file %s)",
+		msgSourceUnnamed:       "<unnamed>",
+		msgAliasConflict:       "Alias <%s> from source %s is already defined in source %s",
+		msgIncludeCycle:        "Include cycle detected: %s -> %s",
+		msgIncludeDepth:        "Maximum include depth %d exceeded (file %s)",
+		msgIncludeItemType:     "Value of %s must be a string or a list of strings, but contains %T",
+		msgIncludeType:         "Value of %s must be a string or a list of strings, not %T",
+		msgRootNotBlock:        "Configuration file must contain a key-value block of aliases",
+		msgTargetNotPointer:    "Target must be passed as a pointer, not as a value of type %T",
+		msgTargetNil:           "Target must not be a nil pointer %T",
+		msgTargetNotStruct:     "Target must be a pointer to a struct, not %T",
+		msgOverflow:            "Value %v overflows field of type %s",
+		msgNegativeUnsigned:    "Negative value %v cannot be set into unsigned field of type %s",
+		msgUnmarshalFailed:     "%s of type %s returned an error",
+		msgDecoderFailed:       "Decoder registered for type %s returned an error",
+		msgDecoderResultType:   "Decoder returned value of type %s instead of %s",
+		msgTimeLayout:          "Value %s does not match any of time layouts (%s)",
+		msgUnknownLocation:     "Unknown time zone %s",
+		msgByteSizeFormat:      "Value %s is not a byte size (for example 512MiB or 10GB)",
+		msgNegativeByteSize:    "Negative size %v cannot be set into field of type %s",
+		msgUnitUnknown:         "Unit tag contains unknown unit %s",
+		msgUnitNotInteger:      "Field has unit tag but is not an integer (type %s)",
+		msgDecodeFailed:        "Cannot decode value",
+		msgKindMissing:         "Value is not set",
+		msgKindType:            "Type mismatch",
+		msgKindMin:             "Value is less than minimum",
+		msgKindMax:             "Value is greater than maximum",
+		msgKindEnum:            "Value is not in enumeration",
+		msgKindEnv:             "Environment variable is not found",
+		msgKindTag:             "Invalid struct tag",
+		msgKindRange:           "Value is out of type range",
+	},
+}
+
+/*	Текст сообщения на выбранном языке. Для неизвестного языка используется русский  */
+func message(lang string, id messageID) string {
+	catalog, exists := catalogs[lang]
+	if exists == false {
+		catalog = catalogs[LanguageRussian]
+	}
+	return catalog[id]
+}
+
+func (this *Configurator) errorf(id messageID, args ...interface{}) error {
+	return fmt.Errorf(message(this.lang, id), args...)
+}
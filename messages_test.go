@@ -0,0 +1,113 @@
+package yaml
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMessages(t *testing.T) {
+	t.Run("catalogs are complete", func(t *testing.T) {
+		verbs := regexp.MustCompile(`%[a-zA-Z]`)
+		for id := msgAliasNotFound; id <= msgKindRange; id++ {
+			ru := catalogs[LanguageRussian][id]
+			en := catalogs[LanguageEnglish][id]
+			if ru == "" || en == "" {
+				t.Errorf("Fail: message %d is missing (ru %q, en %q)", id, ru, en)
+				continue
+			}
+			if strings.Join(verbs.FindAllString(ru, -1), "") != strings.Join(verbs.FindAllString(en, -1), "") {
+				t.Errorf("Fail: message %d has different format verbs (ru %q, en %q)", id, ru, en)
+			}
+		}
+	})
+
+	t.Run("english", func(t *testing.T) {
+		config := NewConfigurator(WithLanguage(LanguageEnglish))
+		if err := config.ReadBytes([]byte(`
+            Alias:
+                Port: 0
+                Level: trace
+                Count: many
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			Host  string `conf:"Host"`
+			Port  int    `conf:"Port" min:"1"`
+			Level string `conf:"Level" enum:"debug;info"`
+			Count int    `conf:"Count"`
+		}
+		err := config.ParseToStruct(&DtoType{}, "Alias")
+		if err == nil {
+			t.Errorf("Fail: no error but it should be")
+			t.FailNow()
+		}
+		for _, expected := range []string{
+			"Value is not set (field Host, alias Alias)",
+			"Value 0 in the configuration file is less than 1 set by min tag (field Port, alias Alias)",
+			"Value does not match any of enum values (debug;info) (field Level, alias Alias)",
+			"Cannot set value of type string into field of type int (field Count, alias Alias)",
+		} {
+			if strings.Contains(err.Error(), expected) == false {
+				t.Errorf("Fail: error does not contain %q: %s", expected, err)
+			}
+		}
+
+		if err := config.ParseToStruct(&DtoType{}, "NotExist"); err == nil || err.Error() != "Alias is missing in the configuration file (alias NotExist)" {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+		if err := config.ReadBytes([]byte("- Alias\n")); err == nil || strings.Contains(err.Error(), "must contain a key-value block") == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+	})
+
+	t.Run("russian by default", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadBytes([]byte("Alias:\n    Port: 1\n")); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			Host string `conf:"Host"`
+		}
+		if err := config.ParseToStruct(&DtoType{}, "Alias"); err == nil || err.Error() != "1:1: Не задано значение (поле Host, алиас Alias)" {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+
+		config = NewConfigurator(WithLanguage("de"))
+		if err := config.ReadBytes([]byte("Alias:\n    Port: 1\n")); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		if err := config.ParseToStruct(&DtoType{}, "Alias"); err == nil || err.Error() != "1:1: Не задано значение (поле Host, алиас Alias)" {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+	})
+
+	t.Run("wrapped errors", func(t *testing.T) {
+		type DtoType struct {
+			V int `conf:"V"`
+		}
+		for lang, expected := range map[string]string{
+			LanguageEnglish: "2:6: Cannot decode value (yaml: cannot decode !!str `abc` as a !!int) (field V, alias A)",
+			LanguageRussian: "2:6: Невозможно разобрать значение (yaml: cannot decode !!str `abc` as a !!int) (поле V, алиас A)",
+		} {
+			config := NewConfigurator(WithLanguage(lang))
+			if err := config.ReadBytes([]byte("A:\n  V: !!int abc\n")); err != nil {
+				t.Errorf("Error while reading source yaml: %s", err)
+				t.FailNow()
+			}
+			if err := config.ParseToStruct(&DtoType{}, "A"); err == nil || err.Error() != expected {
+				t.Errorf("Fail: expected %q got %v", expected, err)
+			}
+		}
+
+		/*	Ошибка без собственного сообщения выводится текстом своего вида  */
+		err := &FieldError{Kind: KindRange, Alias: "A", Path: "V", lang: LanguageEnglish}
+		if err.Error() != "Value is out of type range (field V, alias A)" {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+	})
+}
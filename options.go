@@ -0,0 +1,77 @@
+package yaml
+
+/*	Опция конструктора NewConfigurator  */
+type Option func(*Configurator)
+
+/*	Стратегия слияния списков при загрузке нескольких источников  */
+type ListStrategy int
+
+const (
+	/*	Список из более позднего источника заменяет предыдущий (по умолчанию)  */
+	ListReplace ListStrategy = iota
+	/*	Элементы списка из более позднего источника дописываются в конец предыдущего  */
+	ListAppend
+)
+
+/*	Задает стратегию слияния списков для ReadFiles, ReadDir, MergeFile и MergeBytes  */
+func WithListStrategy(strategy ListStrategy) Option {
+	return func(this *Configurator) {
+		this.listStrategy = strategy
+	}
+}
+
+/*	Поведение при повторном определении алиаса в нескольких источниках  */
+type ConflictPolicy int
+
+const (
+	/*	Алиас из более позднего источника сливается с ранее загруженным (по умолчанию)  */
+	ConflictOverride ConflictPolicy = iota
+	/*	Повторное определение алиаса считается ошибкой  */
+	ConflictError
+)
+
+/*	Задает поведение при повторном определении алиаса в ReadFiles, ReadDir и Merge*  */
+func WithConflictPolicy(policy ConflictPolicy) Option {
+	return func(this *Configurator) {
+		this.conflictPolicy = policy
+	}
+}
+
+/*	Задает максимальную глубину вложенности $include (по умолчанию 10)  */
+func WithIncludeDepth(depth int) Option {
+	return func(this *Configurator) {
+		this.maxInclude = depth
+	}
+}
+
+func (this *Configurator) includeDepth() int {
+	if this.maxInclude <= 0 {
+		return defaultIncludeDepth
+	}
+	return this.maxInclude
+}
+
+/*	Прекращать заполнение структуры на первой ошибке. По умолчанию ParseToStruct
+**	обходит всю структуру и возвращает все найденные ошибки в ParseErrors  */
+func WithFailFast() Option {
+	return func(this *Configurator) {
+		this.failFast = true
+	}
+}
+
+/*	Строгая проверка типов: скалярное значение должно совпадать с видом поля. Строка "42"
+**	не заполняет числовое поле, а число или true - строковое. Целые числа по-прежнему
+**	заполняют вещественные поля, а time.Duration задается строкой  */
+func WithStrictTypes() Option {
+	return func(this *Configurator) {
+		this.strictTypes = true
+	}
+}
+
+/*	Задает язык сообщений об ошибках: LanguageRussian (по умолчанию) или LanguageEnglish.
+**	Для неизвестного языка используются сообщения на русском  */
+func WithLanguage(lang string) Option {
+	return func(this *Configurator) {
+		this.lang = lang
+	}
+}
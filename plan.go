@@ -0,0 +1,453 @@
+package yaml
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*	Планы заполнения структур (reflect.Type -> *structPlan). Тэги полей разбираются
+**	один раз для каждого типа, повторные вызовы ParseToStruct используют готовый план  */
+var structPlans sync.Map
+
+/*	План заполнения структуры: поля с тэгом conf в порядке объявления  */
+type structPlan struct {
+	fields []*fieldPlan
+}
+
+/*	Разобранные тэги одного поля структуры  */
+type fieldPlan struct {
+	index       int
+	name        string
+	ftype       reflect.Type
+	tag         reflect.StructTag
+	options     confTagOptions
+	tagErr      *FieldError // ошибка в тэгах поля, возвращается при каждом его заполнении
+	defaultNode *yaml.Node  // значение тэга default или nil
+	defaultText *yaml.Node  // значение тэга default строкой (для зарегистрированных декодеров)
+	defaultErr  *FieldError // ошибка разбора тэга default, если для типа нет декодера
+	env         bool
+	min         *bound
+	max         *bound
+	enum        *enumSet
+}
+
+/*	Граница тэга min или max, разобранная в соответствии с видом поля  */
+type bound struct {
+	tag     string
+	integer int64
+	float   float64
+}
+
+/*	Допустимые значения тэга enum, разобранные в соответствии с видом поля  */
+type enumSet struct {
+	tag      string
+	integers []int64
+	floats   []float64
+	strings  []string
+}
+
+/*	Кэширование планов. Отключается только в бенчмарках, чтобы сравнить заполнение
+**	с разбором тэгов для каждой структуры  */
+var structPlanCache = true
+
+func structPlanOf(stype reflect.Type) *structPlan {
+	if structPlanCache == false {
+		return newStructPlan(stype)
+	}
+	if plan, exists := structPlans.Load(stype); exists == true {
+		return plan.(*structPlan)
+	}
+	plan, _ := structPlans.LoadOrStore(stype, newStructPlan(stype))
+	return plan.(*structPlan)
+}
+
+func newStructPlan(stype reflect.Type) *structPlan {
+	plan := &structPlan{}
+	for i := 0; i < stype.NumField(); i++ {
+		structField := stype.Field(i)
+		name, options, err := parseConfTag(structField.Tag.Get("conf"))
+		if name == "" || name == "-" {
+			continue
+		}
+		field := &fieldPlan{
+			index:   i,
+			name:    name,
+			ftype:   structField.Type,
+			tag:     structField.Tag,
+			options: options,
+		}
+		if err == nil {
+			err = field.compileTags(structField.Tag)
+		}
+		field.tagErr = err
+		plan.fields = append(plan.fields, field)
+	}
+	return plan
+}
+
+/*	Разбор тэгов default, min, max, env и enum. Для указателей тэги min, max, env и enum
+**	относятся к значению, на которое указывает поле  */
+func (this *fieldPlan) compileTags(tag reflect.StructTag) *FieldError {
+	kind := this.ftype.Kind()
+	if kind == reflect.Ptr {
+		kind = this.ftype.Elem().Kind()
+	}
+	/*	Для размеров в байтах тэги min, max, enum и default записываются так же как значение  */
+	bytes := false
+	if unitTag, exists := tag.Lookup("unit"); exists == true {
+		if unitTag != "bytes" {
+			return tagError(nil, msgUnitUnknown, unitTag)
+		}
+		if isIntegerKind(elemType(this.ftype).Kind()) == false {
+			return tagError(nil, msgUnitNotInteger, this.ftype.String())
+		}
+		bytes = true
+	}
+	if elemType(this.ftype) == byteSizeType {
+		bytes = true
+	}
+	if defaultTag, exists := tag.Lookup("default"); exists == true && this.options.required == false {
+		/*	Тип поля может иметь декодер, зарегистрированный в конкретном Configurator,
+		**	поэтому ошибка разбора тэга проверяется только при заполнении поля  */
+		this.defaultText = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: defaultTag}
+		if defaultValue, err := parseDefaultValue(this.ftype, defaultTag, bytes); err != nil {
+			this.defaultErr = err
+		} else {
+			this.defaultNode = &yaml.Node{}
+			if err := this.defaultNode.Encode(defaultValue); err != nil {
+				this.defaultNode = nil
+				this.defaultErr = tagError(err, msgDefaultParse, defaultTag, this.ftype.String())
+			}
+		}
+	}
+	if minTag := tag.Get("min"); minTag != "" && minTag != "-" {
+		if isCountableKind(kind) == false {
+			return tagError(nil, msgMinNotCountable)
+		}
+		min, err := parseBound(kind, minTag, bytes, msgMinParseInt, msgMinParseFloat)
+		if err != nil {
+			return err
+		}
+		this.min = min
+	}
+	if maxTag := tag.Get("max"); maxTag != "" && maxTag != "-" {
+		if isCountableKind(kind) == false {
+			return tagError(nil, msgMaxNotCountable)
+		}
+		max, err := parseBound(kind, maxTag, bytes, msgMaxParseInt, msgMaxParseFloat)
+		if err != nil {
+			return err
+		}
+		this.max = max
+	}
+	if envTag := tag.Get("env"); envTag != "" && envTag != "-" {
+		result, err := strconv.ParseBool(envTag)
+		if err != nil || result != true {
+			return tagError(nil, msgEnvNotTrue)
+		}
+		if kind != reflect.String {
+			return tagError(nil, msgEnvNotString)
+		}
+		this.env = true
+	}
+	if enumTag := tag.Get("enum"); enumTag != "" {
+		if isCountableKind(kind) == false && kind != reflect.String {
+			return tagError(nil, msgEnumNotSupported)
+		}
+		enum, err := parseEnum(kind, enumTag, bytes)
+		if err != nil {
+			return err
+		}
+		this.enum = enum
+	}
+	return nil
+}
+
+/*	Ошибка тэгов поля. Возвращается копия, так как план общий для всех вызовов  */
+func (this *fieldPlan) tagError() *FieldError {
+	fieldErr := *this.tagErr
+	return &fieldErr
+}
+
+/*	Ошибка разбора тэга default. Возвращается копия, так как план общий для всех вызовов  */
+func (this *fieldPlan) defaultError() *FieldError {
+	fieldErr := *this.defaultErr
+	return &fieldErr
+}
+
+func (this *fieldPlan) hasChecks() bool {
+	return this.min != nil || this.max != nil || this.enum != nil
+}
+
+/*	Проверка заполненного значения тэгами min, max и enum.
+**	Для указателей проверяется значение, на которое указывает поле (nil не проверяется)  */
+func (this *fieldPlan) check(value reflect.Value) *FieldError {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() == true {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if this.min != nil && compareBound(value, this.min) < 0 {
+		return boundError(KindMin, value.Interface(), this.min.tag)
+	}
+	if this.max != nil && compareBound(value, this.max) > 0 {
+		return boundError(KindMax, value.Interface(), this.max.tag)
+	}
+	if this.enum != nil && this.enum.contains(value) == false {
+		return &FieldError{
+			Kind:     KindEnum,
+			Expected: this.enum.tag,
+			Got:      fmt.Sprint(value.Interface()),
+			message:  msgEnumMismatch,
+			args:     []interface{}{this.enum.tag},
+		}
+	}
+	return nil
+}
+
+func isCountableKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isFloatKind(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+/*	Тип значений поля без указателей, срезов и словарей: для []*int это int  */
+func elemType(ftype reflect.Type) reflect.Type {
+	for {
+		switch ftype.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			ftype = ftype.Elem()
+		default:
+			return ftype
+		}
+	}
+}
+
+func parseBound(kind reflect.Kind, tag string, bytes bool, intID, floatID messageID) (*bound, *FieldError) {
+	if bytes == true {
+		value, err := parseByteSizeTag(tag)
+		if err != nil {
+			return nil, tagError(err, intID)
+		}
+		return &bound{tag: tag, integer: value}, nil
+	}
+	if isFloatKind(kind) == true {
+		value, err := parseFloat(kind, tag)
+		if err != nil {
+			return nil, tagError(err, floatID)
+		}
+		return &bound{tag: tag, float: value}, nil
+	}
+	value, err := strconv.ParseInt(tag, 0, 64)
+	if err != nil {
+		return nil, tagError(err, intID)
+	}
+	return &bound{tag: tag, integer: value}, nil
+}
+
+/*	Разбор числа из тэга. Для float32 число округляется до float32, чтобы
+**	значение поля, равное тэгу, не оказалось меньше или больше него  */
+func parseFloat(kind reflect.Kind, tag string) (float64, error) {
+	if kind == reflect.Float32 {
+		value, err := parseFloatString(tag, 32)
+		return float64(float32(value)), err
+	}
+	return parseFloatString(tag, 64)
+}
+
+/*	Сравнение числового значения с границей: -1 меньше, 0 равно, 1 больше  */
+func compareBound(value reflect.Value, bound *bound) int {
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return compareFloats(value.Float(), bound.float)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if bound.integer < 0 || value.Uint() > math.MaxInt64 {
+			return 1
+		}
+		return compareIntegers(int64(value.Uint()), bound.integer)
+	default:
+		return compareIntegers(value.Int(), bound.integer)
+	}
+}
+
+func compareIntegers(value, bound int64) int {
+	switch {
+	case value < bound:
+		return -1
+	case value > bound:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloats(value, bound float64) int {
+	switch {
+	case value < bound:
+		return -1
+	case value > bound:
+		return 1
+	default:
+		return 0
+	}
+}
+
+/*	Разбор вариантов тэга enum, перечисленных через символ ;  */
+func parseEnum(kind reflect.Kind, enumTag string, bytes bool) (*enumSet, *FieldError) {
+	enum := &enumSet{tag: enumTag}
+	for _, enumItem := range strings.Split(enumTag, ";") {
+		switch {
+		case bytes == true:
+			value, err := parseByteSizeTag(enumItem)
+			if err != nil {
+				return nil, tagError(err, msgEnumParseInt, enumItem)
+			}
+			enum.integers = append(enum.integers, value)
+		case kind == reflect.String:
+			enum.strings = append(enum.strings, enumItem)
+		case isFloatKind(kind) == true:
+			value, err := parseFloat(kind, enumItem)
+			if err != nil {
+				return nil, tagError(err, msgEnumParseFloat, enumItem)
+			}
+			enum.floats = append(enum.floats, value)
+		default:
+			value, err := strconv.ParseInt(enumItem, 0, 64)
+			if err != nil {
+				return nil, tagError(err, msgEnumParseInt, enumItem)
+			}
+			enum.integers = append(enum.integers, value)
+		}
+	}
+	return enum, nil
+}
+
+func (this *enumSet) contains(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String:
+		for _, item := range this.strings {
+			if value.String() == item {
+				return true
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		for _, item := range this.floats {
+			if compareFloats(value.Float(), item) == 0 {
+				return true
+			}
+		}
+	default:
+		for _, item := range this.integers {
+			if compareBound(value, &bound{integer: item}) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*	Опции тэга conf, перечисляемые через запятую после имени поля  */
+type confTagOptions struct {
+	optional  bool
+	required  bool
+	omitEmpty bool
+}
+
+func parseConfTag(confTag string) (string, confTagOptions, *FieldError) {
+	var tagOptions confTagOptions
+	parts := strings.Split(confTag, ",")
+	for _, option := range parts[1:] {
+		switch strings.TrimSpace(option) {
+		case "optional":
+			tagOptions.optional = true
+		case "required":
+			tagOptions.required = true
+		case "omitempty":
+			tagOptions.omitEmpty = true
+		case "":
+		default:
+			return parts[0], tagOptions, tagError(nil, msgConfUnknownOption, option)
+		}
+	}
+	if tagOptions.required == true && (tagOptions.optional == true || tagOptions.omitEmpty == true) {
+		return parts[0], tagOptions, tagError(nil, msgConfConflictOptions)
+	}
+	return parts[0], tagOptions, nil
+}
+
+/*	Преобразование значения тэга default к тому же виду, который выдает yaml декодер,
+**	чтобы к нему применялись те же проверки (min max enum) и преобразования что и к значениям
+**	из конфигурационника. Элементы срезов перечисляются через символ ;. bytes - поле
+**	является размером в байтах (ByteSize или тэг unit:"bytes")  */
+func parseDefaultValue(ftype reflect.Type, defaultTag string, bytes bool) (interface{}, *FieldError) {
+	/*	Типы времени, размеры в байтах и типы с методами UnmarshalConf, UnmarshalText
+	**	и UnmarshalJSON получают значение тэга строкой  */
+	if isTimeType(ftype) == true || isSelfDecoding(ftype) == true || (bytes == true && isIntegerKind(ftype.Kind()) == true) {
+		return defaultTag, nil
+	}
+	switch ftype.Kind() {
+	case reflect.Ptr:
+		return parseDefaultValue(ftype.Elem(), defaultTag, bytes)
+	case reflect.Slice:
+		if defaultTag == "" {
+			return []interface{}{}, nil
+		}
+		parts := strings.Split(defaultTag, ";")
+		result := make([]interface{}, len(parts))
+		for j, part := range parts {
+			value, err := parseDefaultValue(ftype.Elem(), part, bytes)
+			if err != nil {
+				return nil, err
+			}
+			result[j] = value
+		}
+		return result, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		/*	time.Duration разбирается в primitiveType из строки  */
+		if ftype.String() == "time.Duration" {
+			return defaultTag, nil
+		}
+		int64Val, err := strconv.ParseInt(defaultTag, 0, 64)
+		if err != nil {
+			return nil, tagError(err, msgDefaultParse, defaultTag, ftype.String())
+		}
+		return int64Val, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		uint64Val, err := strconv.ParseUint(strings.TrimPrefix(defaultTag, "+"), 0, 64)
+		if err != nil {
+			return nil, tagError(err, msgDefaultParse, defaultTag, ftype.String())
+		}
+		return uint64Val, nil
+	case reflect.Float32, reflect.Float64:
+		float64Val, err := parseFloatString(defaultTag, 64)
+		if err != nil {
+			return nil, tagError(err, msgDefaultParse, defaultTag, ftype.String())
+		}
+		return float64Val, nil
+	case reflect.Bool:
+		boolVal, err := strconv.ParseBool(defaultTag)
+		if err != nil {
+			return nil, tagError(err, msgDefaultParse, defaultTag, ftype.String())
+		}
+		return boolVal, nil
+	case reflect.String:
+		return defaultTag, nil
+	default:
+		return nil, tagError(nil, msgDefaultUnsupported, ftype.String())
+	}
+}
@@ -0,0 +1,166 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type benchReplicaType struct {
+	Host    string  `conf:"Host"`
+	Port    int     `conf:"Port" min:"1" max:"65535"`
+	Weight  float64 `conf:"Weight" min:"0" max:"1"`
+	SslMode string  `conf:"SslMode" enum:"disable;require;verify-full"`
+	Timeout string  `conf:"Timeout" default:"5s"`
+}
+
+type benchClusterType struct {
+	Name     string             `conf:"Name"`
+	Replicas []benchReplicaType `conf:"Replicas"`
+}
+
+/*	Кластер из replicas реплик. merge - общие поля реплик задаются через якорь и ключ слияния <<  */
+func benchClusterSource(replicas int, merge bool) []byte {
+	var builder strings.Builder
+	builder.WriteString("Defaults: &defaults\n    Weight: 0.5\n    SslMode: require\n")
+	builder.WriteString("Cluster:\n    Name: main\n    Replicas:\n")
+	for i := 0; i < replicas; i++ {
+		if merge == true {
+			fmt.Fprintf(&builder, "    - <<: *defaults\n      Host: replica%d\n      Port: %d\n", i, 5000+i)
+		} else {
+			fmt.Fprintf(&builder, "    - Host: replica%d\n      Port: %d\n      Weight: 0.5\n      SslMode: require\n", i, 5000+i)
+		}
+	}
+	return []byte(builder.String())
+}
+
+func TestStructPlan(t *testing.T) {
+	t.Run("cached", func(t *testing.T) {
+		plan := structPlanOf(reflect.TypeOf(benchReplicaType{}))
+		if structPlanOf(reflect.TypeOf(benchReplicaType{})) != plan {
+			t.Errorf("Fail: plan is not cached")
+		}
+		if len(plan.fields) != 5 || plan.fields[1].min == nil || plan.fields[1].max.integer != 65535 || len(plan.fields[3].enum.strings) != 3 {
+			t.Errorf("Fail: unexpected plan %#v", plan.fields)
+		}
+	})
+
+	t.Run("tag errors are not shared", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadBytes([]byte("First:\n    Count: 1\nSecond:\n    Count: 2\n")); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			Count int `conf:"Count" min:"one"`
+		}
+		var first, second *FieldError
+		if err := config.ParseToStruct(&DtoType{}, "First"); errors.As(err, &first) == false || errors.Is(err, ErrTag) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+			t.FailNow()
+		}
+		if err := config.ParseToStruct(&DtoType{}, "Second"); errors.As(err, &second) == false || errors.Is(err, ErrTag) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+			t.FailNow()
+		}
+		if first == second || first.Alias != "First" || second.Alias != "Second" {
+			t.Errorf("Fail: tag errors are shared %v %v", first, second)
+		}
+	})
+
+	t.Run("checks after conversion", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadBytes([]byte("Alias:\n    Port: 70000\n    Ratio: 0.5\n    Level: '2'\n")); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			Port  int     `conf:"Port" max:"65535"`
+			Ratio float32 `conf:"Ratio" min:"0.5" max:"0.5"`
+			Level uint    `conf:"Level" enum:"1;2"`
+		}
+		dto := DtoType{Port: 42}
+		err := config.ParseToStruct(&dto, "Alias")
+		if errors.Is(err, ErrMax) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+		if dto.Port != 42 || dto.Ratio != 0.5 || dto.Level != 2 {
+			t.Errorf("Fail: unexpected dto %#v", dto)
+		}
+	})
+}
+
+/*	Заполнение большого списка структур. Сравниваются план из кэша и разбор тэгов для каждого
+**	элемента списка (без кэша), блоки без ключей слияния и с ними (пары ключ-значение собираются
+**	для каждого поиска поля), а также заполнение тех же структур напрямую декодером yaml.v3  */
+func BenchmarkParseToStruct(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		merge bool
+		cache bool
+	}{
+		{name: "cached plan", cache: true},
+		{name: "uncached plan", cache: false},
+		{name: "merge keys", merge: true, cache: true},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			config := NewConfigurator()
+			if err := config.ReadBytes(benchClusterSource(1000, bench.merge)); err != nil {
+				b.Fatalf("Error while reading source yaml: %s", err)
+			}
+			structPlanCache = bench.cache
+			defer func() { structPlanCache = true }()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var cluster benchClusterType
+				if err := config.ParseToStruct(&cluster, "Cluster"); err != nil {
+					b.Fatalf("Error while filling config: %s", err)
+				}
+			}
+		})
+	}
+	b.Run("yaml.v3 decode", func(b *testing.B) {
+		var root yaml.Node
+		if err := yaml.Unmarshal(benchClusterSource(1000, false), &root); err != nil {
+			b.Fatalf("Error while reading source yaml: %s", err)
+		}
+		cluster := mappingValue(root.Content[0], "Cluster")
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			var dto struct {
+				Name     string `yaml:"Name"`
+				Replicas []struct {
+					Host    string  `yaml:"Host"`
+					Port    int     `yaml:"Port"`
+					Weight  float64 `yaml:"Weight"`
+					SslMode string  `yaml:"SslMode"`
+				} `yaml:"Replicas"`
+			}
+			if err := cluster.Decode(&dto); err != nil {
+				b.Fatalf("Error while filling config: %s", err)
+			}
+		}
+	})
+}
+
+/*	Построение плана одной структуры против получения его из кэша  */
+func BenchmarkStructPlanUncached(b *testing.B) {
+	stype := reflect.TypeOf(benchReplicaType{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		newStructPlan(stype)
+	}
+}
+
+func BenchmarkStructPlanCached(b *testing.B) {
+	stype := reflect.TypeOf(benchReplicaType{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		structPlanOf(stype)
+	}
+}
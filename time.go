@@ -0,0 +1,119 @@
+package yaml
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"strings"
+	"time"
+)
+
+/*	Время суток без даты (окна обслуживания, время запуска задач).
+**	В конфигурационнике задается строкой вида 15:04 или 15:04:05  */
+type TimeOfDay struct {
+	Hour   int
+	Minute int
+	Second int
+}
+
+func (this TimeOfDay) String() string {
+	if this.Second == 0 {
+		return fmt.Sprintf("%02d:%02d", this.Hour, this.Minute)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", this.Hour, this.Minute, this.Second)
+}
+
+/*	Момент этого времени суток в день date (в часовом поясе date)  */
+func (this TimeOfDay) On(date time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, this.Hour, this.Minute, this.Second, 0, date.Location())
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	locationType  = reflect.TypeOf((*time.Location)(nil))
+	timeOfDayType = reflect.TypeOf(TimeOfDay{})
+)
+
+/*	Форматы по умолчанию. Время без часового пояса считается временем в UTC  */
+var (
+	defaultTimeLayouts      = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+	defaultTimeOfDayLayouts = []string{"15:04:05", "15:04"}
+)
+
+/*	Типы времени, которые заполняются из строки встроенными преобразованиями  */
+func isTimeType(ftype reflect.Type) bool {
+	return ftype == timeType || ftype == locationType || ftype == timeOfDayType
+}
+
+/*	Заполнение time.Time, *time.Location и TimeOfDay. Тэг layout задает собственные
+**	форматы времени через символ ;. Возвращает false для остальных типов  */
+func (this *Configurator) decodeTime(ctx *parseContext, field reflect.Value, node *yaml.Node, ftype reflect.Type, ftag reflect.StructTag, path string) (bool, error) {
+	if isTimeType(ftype) == false {
+		return false, nil
+	}
+	/*	Как и для остальных указателей, null не считается ошибкой  */
+	if ftype == locationType && isNullNode(node) == true {
+		return true, nil
+	}
+	value, err := nodeValue(node)
+	if err != nil {
+		return true, this.fieldError(ctx, err, path, node)
+	}
+	text, ok := value.(string)
+	if ok == false {
+		return true, this.fieldError(ctx, typeError(ftype, fmt.Sprintf("%T", value)), path, node)
+	}
+	switch ftype {
+	case locationType:
+		location, err := time.LoadLocation(text)
+		if err != nil {
+			return true, this.fieldError(ctx, &FieldError{
+				Kind:     KindType,
+				Expected: ftype.String(),
+				Got:      text,
+				Err:      err,
+				message:  msgUnknownLocation,
+				args:     []interface{}{text},
+			}, path, node)
+		}
+		field.Set(reflect.ValueOf(location))
+	case timeType:
+		parsed, err := parseTime(text, timeLayouts(ftag, defaultTimeLayouts))
+		if err != nil {
+			return true, this.fieldError(ctx, err, path, node)
+		}
+		field.Set(reflect.ValueOf(parsed))
+	case timeOfDayType:
+		parsed, err := parseTime(text, timeLayouts(ftag, defaultTimeOfDayLayouts))
+		if err != nil {
+			return true, this.fieldError(ctx, err, path, node)
+		}
+		field.Set(reflect.ValueOf(TimeOfDay{Hour: parsed.Hour(), Minute: parsed.Minute(), Second: parsed.Second()}))
+	}
+	return true, nil
+}
+
+func timeLayouts(ftag reflect.StructTag, defaults []string) []string {
+	if layoutTag := ftag.Get("layout"); layoutTag != "" {
+		return strings.Split(layoutTag, ";")
+	}
+	return defaults
+}
+
+/*	Разбор времени первым подходящим форматом  */
+func parseTime(text string, layouts []string) (time.Time, *FieldError) {
+	for _, layout := range layouts {
+		if parsed, err := time.Parse(layout, text); err == nil {
+			return parsed, nil
+		}
+	}
+	joined := strings.Join(layouts, ";")
+	return time.Time{}, &FieldError{
+		Kind:     KindType,
+		Expected: joined,
+		Got:      text,
+		message:  msgTimeLayout,
+		args:     []interface{}{text, joined},
+	}
+}
@@ -0,0 +1,102 @@
+package yaml
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTime(t *testing.T) {
+	config := NewConfigurator(WithStrictTypes())
+	if err := config.ReadBytes([]byte(`
+        Maintenance:
+            CutOver: 2024-03-01T02:00:00+03:00
+            Precise: "2024-03-01T02:00:00.123456789Z"
+            Release: 2024-04-15
+            Local: 2024-04-15 10:30:00
+            Legacy: 15.04.2024 10:30
+            Holidays: [2024-01-01, 2024-05-09]
+            Zone: Europe/Moscow
+            Start: "02:30"
+            End: 04:15:30
+            Windows:
+                weekly: "03:00"
+        Broken:
+            CutOver: tomorrow
+            Zone: Mars/Olympus
+            Start: 25:00
+            Release: 20240415
+    `)); err != nil {
+		t.Errorf("Error while reading source yaml: %s", err)
+		t.FailNow()
+	}
+
+	t.Run("time values", func(t *testing.T) {
+		type DtoType struct {
+			CutOver  time.Time            `conf:"CutOver"`
+			Precise  *time.Time           `conf:"Precise"`
+			Release  time.Time            `conf:"Release"`
+			Local    time.Time            `conf:"Local"`
+			Legacy   time.Time            `conf:"Legacy" layout:"02.01.2006;02.01.2006 15:04"`
+			Holidays []time.Time          `conf:"Holidays"`
+			Zone     *time.Location       `conf:"Zone"`
+			Start    TimeOfDay            `conf:"Start"`
+			End      TimeOfDay            `conf:"End"`
+			Windows  map[string]TimeOfDay `conf:"Windows"`
+			Since    time.Time            `conf:"Since" default:"2020-01-01"`
+			Fallback *time.Location       `conf:"Fallback" default:"UTC"`
+			Finish   TimeOfDay            `conf:"Finish" default:"23:59"`
+		}
+		var dto DtoType
+		if err := config.ParseToStruct(&dto, "Maintenance"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.CutOver.Equal(time.Date(2024, 2, 29, 23, 0, 0, 0, time.UTC)) == false {
+			t.Errorf("Fail: unexpected CutOver %v", dto.CutOver)
+		}
+		if dto.Precise == nil || dto.Precise.Nanosecond() != 123456789 {
+			t.Errorf("Fail: unexpected Precise %v", dto.Precise)
+		}
+		if dto.Release != time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC) || dto.Local != time.Date(2024, 4, 15, 10, 30, 0, 0, time.UTC) {
+			t.Errorf("Fail: unexpected dates %v %v", dto.Release, dto.Local)
+		}
+		if dto.Legacy != time.Date(2024, 4, 15, 10, 30, 0, 0, time.UTC) {
+			t.Errorf("Fail: unexpected Legacy %v", dto.Legacy)
+		}
+		if len(dto.Holidays) != 2 || dto.Holidays[1] != time.Date(2024, 5, 9, 0, 0, 0, 0, time.UTC) {
+			t.Errorf("Fail: unexpected Holidays %v", dto.Holidays)
+		}
+		if dto.Zone == nil || dto.Zone.String() != "Europe/Moscow" || dto.Fallback != time.UTC {
+			t.Errorf("Fail: unexpected zones %v %v", dto.Zone, dto.Fallback)
+		}
+		if dto.Start != (TimeOfDay{Hour: 2, Minute: 30}) || dto.End != (TimeOfDay{Hour: 4, Minute: 15, Second: 30}) {
+			t.Errorf("Fail: unexpected clock %v %v", dto.Start, dto.End)
+		}
+		if dto.Windows["weekly"].String() != "03:00" || dto.Finish.String() != "23:59" || dto.End.String() != "04:15:30" {
+			t.Errorf("Fail: unexpected clock %v %v", dto.Windows, dto.Finish)
+		}
+		if dto.Since != time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC) {
+			t.Errorf("Fail: unexpected Since %v", dto.Since)
+		}
+		if start := dto.Start.On(dto.Release.In(dto.Zone)); start.Hour() != 2 || start.Day() != 15 || start.Location() != dto.Zone {
+			t.Errorf("Fail: unexpected start %v", start)
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		type BrokenType struct {
+			CutOver time.Time      `conf:"CutOver"`
+			Zone    *time.Location `conf:"Zone"`
+			Start   TimeOfDay      `conf:"Start"`
+			Release time.Time      `conf:"Release"`
+		}
+		err := config.ParseToStruct(&BrokenType{}, "Broken")
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false || len(parseErrors) != 4 || errors.Is(err, ErrType) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		} else {
+			t.Logf("Success. %s", err)
+		}
+	})
+}
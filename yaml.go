@@ -1,33 +1,74 @@
 package yaml
 
 import (
+	"errors"
 	"fmt"
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 	"io"
+	"io/fs"
+	"math"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+/*	Конфигуратор безопасен для параллельного использования: загрузка источников
+**	выполняется последовательно, а заполнение структур работает со снимком загруженных данных  */
 type Configurator struct {
-	dataMap       map[string]map[string]interface{}
-	lastAliasName string
+	doc            *document
+	mu             sync.RWMutex
+	loadMu         sync.Mutex
+	listStrategy   ListStrategy
+	conflictPolicy ConflictPolicy
+	maxInclude     int
+	failFast       bool
+	strictTypes    bool
+	lang           string
+	decoders       sync.Map // reflect.Type -> DecoderFunc
 }
 
-func NewConfigurator() *Configurator {
-	return &Configurator{}
+func NewConfigurator(options ...Option) *Configurator {
+	this := &Configurator{lang: LanguageRussian}
+	for _, option := range options {
+		option(this)
+	}
+	return this
 }
 
 func (this *Configurator) ReadFile(fileName string) error {
-	body, err := readFile(fileName)
+	return this.ReadFiles(fileName)
+}
+
+/*	Чтение конфигурации из среза байт (например из встроенных ресурсов)  */
+func (this *Configurator) ReadBytes(src []byte) error {
+	return this.setNewSource(src)
+}
+
+/*	Чтение конфигурации из произвольного источника (например os.Stdin)  */
+func (this *Configurator) ReadReader(reader io.Reader) error {
+	body, err := io.ReadAll(reader)
 	if err != nil {
 		return err
 	}
 	return this.setNewSource(body)
 }
 
+/*	Чтение конфигурации из файловой системы fs.FS (в том числе embed.FS)  */
+func (this *Configurator) ReadFS(fsys fs.FS, fileName string) error {
+	this.loadMu.Lock()
+	defer this.loadMu.Unlock()
+	loader := this.newLoader(false)
+	loader.useFS(fsys)
+	if err := loader.mergeFile(fileName, 0); err != nil {
+		return err
+	}
+	this.commit(loader)
+	return nil
+}
+
 func readFile(fileName string) ([]byte, error) {
 	file, err := os.Open(fileName)
 	if err != nil {
@@ -45,626 +86,742 @@ func readFile(fileName string) ([]byte, error) {
 }
 
 func (this *Configurator) setNewSource(src []byte) error {
-	this.dataMap = nil
-	if err := yaml.Unmarshal(src, &this.dataMap); err != nil {
+	this.loadMu.Lock()
+	defer this.loadMu.Unlock()
+	loader := this.newLoader(false)
+	if err := loader.mergeBytes("", src, 0); err != nil {
 		return err
 	}
+	this.commit(loader)
 	return nil
 }
 
 func (this *Configurator) ParseToStruct(packStruct interface{}, aliasName string) error {
-	structVal := reflect.ValueOf(packStruct).Elem()
-	this.lastAliasName = aliasName
+	structVal, err := this.targetValue(packStruct, true)
+	if err != nil {
+		return err
+	}
+	ctx := this.newParseContext(aliasName)
+	aliasNode := lookupPath(ctx.doc.root, aliasName)
+	if aliasNode == nil {
+		return &FieldError{
+			Alias:   aliasName,
+			Kind:    KindMissing,
+			message: msgAliasNotFound,
+			lang:    this.lang,
+		}
+	}
+	return this.switchSetType(ctx, structVal, aliasNode, structVal.Type(), "", "")
+}
+
+/*	Заполнение структуры, описывающей весь документ: тэги conf полей структуры
+**	задают имена алиасов. Ошибки всех алиасов возвращаются одним списком ParseErrors  */
+func (this *Configurator) ParseAll(packStruct interface{}) error {
+	structVal, err := this.targetValue(packStruct, true)
+	if err != nil {
+		return err
+	}
+	doc := this.snapshot()
+
+	var errs ParseErrors
+	for _, planned := range structPlanOf(structVal.Type()).fields {
+		ctx := &parseContext{alias: planned.name, doc: doc}
+		if err := this.setStructField(ctx, structVal.Field(planned.index), planned, doc.root, ""); err != nil {
+			errs = errs.append(err)
+			if this.failFast == true {
+				return errs
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
 
-	aliasValue, exists := this.dataMap[aliasName]
-	if exists == false {
-		return fmt.Errorf("Алиас <%s> отсутствует в конфигурационном файле", aliasName)
+/*	Заполнение произвольного значения (скаляра, среза, словаря или структуры) из ключа
+**	верхнего уровня, например ParseValue(&level, "LogLevel"). dst - указатель на значение  */
+func (this *Configurator) ParseValue(dst interface{}, key string) error {
+	value, err := this.targetValue(dst, false)
+	if err != nil {
+		return err
 	}
-	return this.switchSetType(structVal, aliasValue, structVal.Type(), "", reflect.Value{})
+	ctx := this.newParseContext(key)
+	node := lookupPath(ctx.doc.root, key)
+	if node == nil {
+		return &FieldError{
+			Alias:   key,
+			Kind:    KindMissing,
+			message: msgAliasNotFound,
+			lang:    this.lang,
+		}
+	}
+	return this.switchSetType(ctx, value, node, value.Type(), "", "")
 }
 
-/*	Рекурсивная функция заполнения полей конфига  */
-func (this *Configurator) switchSetType(field reflect.Value, value interface{}, ftype reflect.Type, ftag reflect.StructTag, map_key reflect.Value) error {
+/*	Типизированное заполнение значения из алиаса: структуры, скаляра, среза или словаря.
+**	Например database, err := Parse[DatabaseConfig](config, "Database")  */
+func Parse[T any](config *Configurator, aliasName string) (T, error) {
+	var value T
+	err := config.ParseValue(&value, aliasName)
+	return value, err
+}
+
+/*	То же что Parse, но вызывает panic в случае ошибки. Удобно для инициализации при старте  */
+func MustParse[T any](config *Configurator, aliasName string) T {
+	value, err := Parse[T](config, aliasName)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+/*	Проверка заполняемого значения: это должен быть ненулевой указатель
+**	(на структуру, если requireStruct). Возвращает значение, на которое он указывает  */
+func (this *Configurator) targetValue(dst interface{}, requireStruct bool) (reflect.Value, error) {
+	pointer := reflect.ValueOf(dst)
+	if pointer.Kind() != reflect.Ptr {
+		return reflect.Value{}, this.errorf(msgTargetNotPointer, dst)
+	}
+	if pointer.IsNil() == true {
+		return reflect.Value{}, this.errorf(msgTargetNil, dst)
+	}
+	if requireStruct == true && pointer.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, this.errorf(msgTargetNotStruct, dst)
+	}
+	return pointer.Elem(), nil
+}
+
+/*	Контекст одного вызова заполнения: имя алиаса для ошибок и снимок загруженных данных.
+**	Передается через рекурсию, поэтому параллельные вызовы не влияют друг на друга  */
+type parseContext struct {
+	alias string
+	doc   *document
+}
+
+func (this *Configurator) newParseContext(aliasName string) *parseContext {
+	return &parseContext{alias: aliasName, doc: this.snapshot()}
+}
+
+/*	Рекурсивная функция заполнения полей конфига из узла yaml. path - путь к полю внутри алиаса
+**	(например Replicas[2].Port). Ошибки вложенных полей собираются в ParseErrors  */
+func (this *Configurator) switchSetType(ctx *parseContext, field reflect.Value, node *yaml.Node, ftype reflect.Type, ftag reflect.StructTag, path string) error {
+	node = resolveAlias(node)
+	if decoder := this.decoderOf(ftype); decoder != nil {
+		return this.decodeRegistered(ctx, decoder, field, node, ftype, path)
+	}
+	if decoded, err := this.decodeTime(ctx, field, node, ftype, ftag, path); decoded == true {
+		return err
+	}
+	if decoded, err := this.decodeByteSize(ctx, field, node, ftype, ftag, path); decoded == true {
+		return err
+	}
+	if decoded, err := this.decodeSelf(ctx, field, node, ftype, path); decoded == true {
+		return err
+	}
 	switch ftype.Kind() {
 	case reflect.Slice:
-		if value != nil {
-			v_slice := reflect.ValueOf(value)
+		if isNullNode(node) == false {
+			if node.Kind != yaml.SequenceNode {
+				return this.fieldError(ctx, typeError(ftype, nodeValueType(node)), path, node)
+			}
 			t_slice := ftype.Elem()
-			slice := reflect.MakeSlice(reflect.SliceOf(t_slice), v_slice.Len(), v_slice.Cap())
-			for j := 0; j < v_slice.Len(); j++ {
-				slice.Index(j).Set(reflect.Zero(t_slice))
-				if err := this.switchSetType(slice.Index(j), v_slice.Index(j).Interface(), t_slice, ftag, reflect.Value{}); err != nil {
-					return fmt.Errorf("%w (поле номер %d)", err, j)
+			slice := reflect.MakeSlice(reflect.SliceOf(t_slice), len(node.Content), len(node.Content))
+			var errs ParseErrors
+			for j, item := range node.Content {
+				if err := this.switchSetType(ctx, slice.Index(j), item, t_slice, ftag, fmt.Sprintf("%s[%d]", path, j)); err != nil {
+					errs = errs.append(err)
+					if this.failFast == true {
+						return errs
+					}
 				}
 			}
-			if field.Type().Kind() == reflect.Map {
-				field.SetMapIndex(map_key, slice)
-			} else {
-				field.Set(slice)
+			if len(errs) > 0 {
+				return errs
 			}
+			field.Set(slice)
 		}
+	/*	Ключи и значения словаря заполняются во временные переменные с типами ключа
+	**	и значения словаря, после чего пара добавляется в словарь  */
 	case reflect.Map:
-		if value != nil {
-			v_map := reflect.ValueOf(value)
-			field.Set(reflect.MakeMap(ftype))
-
-			for _, k := range v_map.MapKeys() {
-				val_json := v_map.MapIndex(k)
-				n_key := reflect.ValueOf(k.Interface().(string))
-				field.SetMapIndex(n_key, reflect.Zero(ftype.Elem()))
-				if err := this.switchSetType(field, val_json.Interface(), ftype.Elem(), ftag, n_key); err != nil {
-					return err
+		if isNullNode(node) == false {
+			if node.Kind != yaml.MappingNode {
+				return this.fieldError(ctx, typeError(ftype, nodeValueType(node)), path, node)
+			}
+			v_map := reflect.MakeMapWithSize(ftype, len(node.Content)/2)
+			var errs ParseErrors
+			pairs := mappingPairs(node)
+			for i := 0; i+1 < len(pairs); i += 2 {
+				keyNode, valueNode := pairs[i], pairs[i+1]
+				childPath := joinPath(path, keyNode.Value)
+				key := reflect.New(ftype.Key()).Elem()
+				if err := this.switchSetType(ctx, key, keyNode, ftype.Key(), "", childPath); err != nil {
+					errs = errs.append(err)
+					if this.failFast == true {
+						return errs
+					}
+					continue
+				}
+				value := reflect.New(ftype.Elem()).Elem()
+				if err := this.switchSetType(ctx, value, valueNode, ftype.Elem(), ftag, childPath); err != nil {
+					errs = errs.append(err)
+					if this.failFast == true {
+						return errs
+					}
+					continue
 				}
+				v_map.SetMapIndex(key, value)
 			}
+			if len(errs) > 0 {
+				return errs
+			}
+			field.Set(v_map)
 		}
 	/*	Обработка структуры. Если нет тега conf - поле не обрабатывается
+	**	Если поле отсутствует в конфигурационнике - используется значение тэга default
+	**	Опции тэга conf optional и omitempty разрешают отсутствие поля (omitempty также и null)
 	**	Для исчислимых можно добавлять тэги min max
 	**	Для строковых можно добавлять тэг env (заполнить поле значением из переменной окружения)
 	**	Для строковых и исчислимых можно добавлять тэг enum - выбор из допустимых значений  */
 	case reflect.Struct:
-		var structValue map[string]interface{}
-		switch typedValue := value.(type) {
-		case map[string]interface{}:
-			structValue = typedValue
-		case map[interface{}]interface{}:
-			structValue = cleanupInterfaceMap(typedValue)
-		default:
-			return fmt.Errorf("Тело структуры невозможно заполнить так как попался необрабатываемый тип %T", value)
-		}
-		for i := 0; i < ftype.NumField(); i++ {
-			tag := ftype.Field(i).Tag.Get("conf")
-			minTag := ftype.Field(i).Tag.Get("min")
-			maxTag := ftype.Field(i).Tag.Get("max")
-			envTag := ftype.Field(i).Tag.Get("env")
-			enumTag := ftype.Field(i).Tag.Get("enum")
-			if tag == "" || tag == "-" {
-				continue
-			}
-			value_child, exist := structValue[tag]
-			if exist == false {
-				return fmt.Errorf("Для поля %s не задано значение (алиас %s)", tag, this.lastAliasName)
-			}
-			if minTag != "" && minTag != "-" {
-				if isCountableType(ftype.Field(i).Type, field.Field(i)) == false {
-					return fmt.Errorf("Поле %s имеет тэг min но при этом не является исчислимым (алиас %s)", tag, this.lastAliasName)
-				}
-				if err := this.checkMinFieldValue(ftype.Field(i).Type, field.Field(i), value_child, minTag); err != nil {
-					return fmt.Errorf("%w (поле %s, алиас %s)", err, tag, this.lastAliasName)
-				}
-			}
-			if maxTag != "" && maxTag != "-" {
-				if isCountableType(ftype.Field(i).Type, field.Field(i)) == false {
-					return fmt.Errorf("Поле %s имеет тэг max но при этом не является исчислимым (алиас %s)", tag, this.lastAliasName)
-				}
-				if err := this.checkMaxFieldValue(ftype.Field(i).Type, field.Field(i), value_child, maxTag); err != nil {
-					return fmt.Errorf("%w (поле %s, алиас %s)", err, tag, this.lastAliasName)
-				}
-			}
-			if envTag != "" && envTag != "-" {
-				result, err := strconv.ParseBool(envTag)
-				if err != nil || result != true {
-					return fmt.Errorf("Поле %s имеет тэг env но при этом не установлено в true (алиас %s)", tag, this.lastAliasName)
-				}
-				if isStringType(ftype.Field(i).Type, field.Field(i)) == false {
-					return fmt.Errorf("Поле %s имеет тэг env но при этом не является строкой (алиас %s)", tag, this.lastAliasName)
-				}
-				string_value_child, ok := value_child.(string)
-				if ok == false {
-					return fmt.Errorf("Поле %s имеет тэг env но при этом не является строкой (алиас %s)", tag, this.lastAliasName)
-				}
-				path, exists := os.LookupEnv(string_value_child)
-				if exists == false {
-					return fmt.Errorf("Поле %s имеет тэг env но переменная окружения %s не обнаружена в системе (алиас %s)", tag, value_child, this.lastAliasName)
-				}
-				value_child = path
-			}
-			if enumTag != "" {
-				if isCountableType(ftype.Field(i).Type, field.Field(i)) == true || isStringType(ftype.Field(i).Type, field.Field(i)) == true {
-					if err := this.checkEnum(ftype.Field(i).Type, field.Field(i), value_child, enumTag); err != nil {
-						return fmt.Errorf("%w (поле %s, алиас %s)", err, tag, this.lastAliasName)
-					}
-				} else {
-					return fmt.Errorf("Поле %s имеет тэг enum но при этом не является ни исчислимым ни строкой (алиас %s)", tag, this.lastAliasName)
+		/*	Пустой блок алиаса считается блоком без полей  */
+		if node.Kind != yaml.MappingNode && (path != "" || isNullNode(node) == false) {
+			valueType := nodeValueType(node)
+			return this.fieldError(ctx, &FieldError{
+				Kind:     KindType,
+				Expected: ftype.String(),
+				Got:      valueType,
+				message:  msgStructBodyType,
+				args:     []interface{}{valueType},
+			}, path, node)
+		}
+		var errs ParseErrors
+		for _, planned := range structPlanOf(ftype).fields {
+			if err := this.setStructField(ctx, field.Field(planned.index), planned, node, joinPath(path, planned.name)); err != nil {
+				errs = errs.append(err)
+				if this.failFast == true {
+					return errs
 				}
 			}
-			/*	Рекурсия  */
-			if err := this.switchSetType(field.Field(i), value_child, ftype.Field(i).Type, ftype.Field(i).Tag, reflect.Value{}); err != nil {
-				return fmt.Errorf("%w (поле %s, алиас %s)", err, tag, this.lastAliasName)
-			}
+		}
+		if len(errs) > 0 {
+			return errs
 		}
 	case reflect.Ptr:
-		if value != nil {
+		if isNullNode(node) == false {
 			/*	Рекурсия. В случае nil из конфигурационника - ошибкой не считается  */
 			field_type_child := field.Type()
 			field.Set(reflect.New(field_type_child.Elem()))
-			if err := this.switchSetType(field.Elem(), value, field_type_child.Elem(), ftag, reflect.Value{}); err != nil {
+			if err := this.switchSetType(ctx, field.Elem(), node, field_type_child.Elem(), ftag, path); err != nil {
 				return err
 			}
 		}
 	default:
-		val, err := this.primitiveType(ftype, value, ftag)
+		value, err := nodeValue(node)
 		if err != nil {
-			return err
+			return this.fieldError(ctx, err, path, node)
 		}
-		if field.CanAddr() && field.Type().Kind() != reflect.Map {
-			field.Set(val)
-		} else if field.Type().Kind() == reflect.Map {
-			field.SetMapIndex(map_key, val)
+		val, err := this.primitiveType(ftype, value, ftag)
+		if err != nil {
+			return this.fieldError(ctx, err, path, node)
 		}
+		field.Set(val)
 	}
 	return nil
 }
 
-func cleanupInterfaceMap(in map[interface{}]interface{}) map[string]interface{} {
-	res := make(map[string]interface{})
-	for k, v := range in {
-		res[fmt.Sprintf("%v", k)] = cleanupMapValue(v)
+/*	Заполнение одного поля структуры по плану (разобранным тэгам). structNode - узел блока,
+**	содержащего поле (его позиция используется для ошибок отсутствующих полей)  */
+func (this *Configurator) setStructField(ctx *parseContext, field reflect.Value, plan *fieldPlan, structNode *yaml.Node, path string) error {
+	if plan.tagErr != nil {
+		return this.fieldError(ctx, plan.tagError(), path, structNode)
+	}
+	/*	Для типов с зарегистрированным декодером значение тэга default передается ему строкой  */
+	defaultNode := plan.defaultNode
+	if plan.defaultText != nil && this.hasDecoder(plan.ftype) == true {
+		defaultNode = plan.defaultText
+	} else if plan.defaultErr != nil {
+		return this.fieldError(ctx, plan.defaultError(), path, structNode)
+	}
+	node_child := lookupPath(structNode, plan.name)
+	if node_child != nil && isNullNode(node_child) == true && plan.options.omitEmpty == true {
+		return nil
+	}
+	/*	Узел, позиция которого указывается в ошибках проверки значения  */
+	positionNode := node_child
+	if node_child == nil {
+		if defaultNode == nil {
+			if plan.options.optional == true || plan.options.omitEmpty == true {
+				return nil
+			}
+			/*	Пустой путь - поле корневой структуры ParseAll, то есть отсутствует сам алиас  */
+			if path == "" {
+				return this.fieldError(ctx, &FieldError{Kind: KindMissing, message: msgAliasNotFound}, path, structNode)
+			}
+			return this.fieldError(ctx, &FieldError{Kind: KindMissing, message: msgMissingValue}, path, structNode)
+		}
+		positionNode = structNode
+		node_child = defaultNode
+	}
+	if plan.env == true {
+		value_child, err := nodeValue(node_child)
+		if err != nil {
+			return this.fieldError(ctx, err, path, positionNode)
+		}
+		string_value_child, ok := value_child.(string)
+		if ok == false {
+			return this.fieldError(ctx, &FieldError{
+				Kind:     KindType,
+				Expected: "string",
+				Got:      fmt.Sprintf("%T", value_child),
+				message:  msgEnvValueNotString,
+			}, path, positionNode)
+		}
+		envValue, exists := os.LookupEnv(string_value_child)
+		if exists == false {
+			return this.fieldError(ctx, &FieldError{
+				Kind:     KindEnv,
+				Expected: string_value_child,
+				message:  msgEnvNotFound,
+				args:     []interface{}{string_value_child},
+			}, path, positionNode)
+		}
+		node_child = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: envValue}
+	}
+	if plan.hasChecks() == false {
+		/*	Рекурсия  */
+		return this.switchSetType(ctx, field, node_child, plan.ftype, plan.tag, path)
+	}
+	/*	Тэги min, max и enum проверяют уже преобразованное значение. Поле изменяется
+	**	только если значение прошло проверки  */
+	value := reflect.New(plan.ftype).Elem()
+	if err := this.switchSetType(ctx, value, node_child, plan.ftype, plan.tag, path); err != nil {
+		return err
+	}
+	if err := plan.check(value); err != nil {
+		return this.fieldError(ctx, err, path, positionNode)
 	}
-	return res
+	field.Set(value)
+	return nil
 }
 
-func cleanupMapValue(v interface{}) interface{} {
-	switch v := v.(type) {
-	case []interface{}:
-		return cleanupInterfaceArray(v)
-	case map[interface{}]interface{}:
-		return cleanupInterfaceMap(v)
-	case string:
-		return v
-	default:
-		return v
+/*	Значение узла в виде, который выдает yaml декодер (int, float64, bool, string, срезы и словари)  */
+func nodeValue(node *yaml.Node) (interface{}, error) {
+	if node.Kind == yaml.ScalarNode {
+		if value, ok := scalarValue(node); ok == true {
+			return value, nil
+		}
 	}
-}
-func cleanupInterfaceArray(in []interface{}) []interface{} {
-	res := make([]interface{}, len(in))
-	for i, v := range in {
-		res[i] = cleanupMapValue(v)
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, err
 	}
-	return res
+	return value, nil
 }
 
-func isCountableType(ftype reflect.Type, field reflect.Value) bool {
-	switch ftype.Kind() {
-	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Int, reflect.Int32, reflect.Int64, reflect.Float64, reflect.Float32:
-		return true
-	case reflect.Ptr:
-		switch field.Type().Elem().Kind() {
-		case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Int, reflect.Int32, reflect.Int64, reflect.Float64, reflect.Float32:
-			return true
-		default:
-			return false
+/*	Значение простого скаляра без запуска декодера yaml. false - значение нужно разобрать
+**	декодером (числа с префиксами и разделителями, .inf, yes/no и т.д.)  */
+func scalarValue(node *yaml.Node) (interface{}, bool) {
+	switch node.ShortTag() {
+	case "!!str":
+		return node.Value, true
+	case "!!null":
+		switch node.Value {
+		case "", "~", "null", "Null", "NULL":
+			return nil, true
+		}
+	case "!!bool":
+		switch node.Value {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	case "!!int":
+		if isDecimalInteger(node.Value) == true {
+			if value, err := strconv.ParseInt(node.Value, 10, 0); err == nil {
+				return int(value), true
+			}
+		}
+	case "!!float":
+		if strings.Trim(node.Value, "0123456789.eE+-") == "" {
+			if value, err := strconv.ParseFloat(node.Value, 64); err == nil {
+				return value, true
+			}
 		}
-	default:
-		return false
 	}
+	return nil, false
 }
 
-func isStringType(ftype reflect.Type, field reflect.Value) bool {
-	switch ftype.Kind() {
-	case reflect.String:
-		return true
-	case reflect.Ptr:
-		switch field.Type().Elem().Kind() {
-		case reflect.String:
-			return true
-		default:
-			return false
-		}
-	default:
+/*	Десятичное целое без ведущих нулей и разделителей  */
+func isDecimalInteger(value string) bool {
+	digits := strings.TrimLeft(value, "+-")
+	if len(value)-len(digits) > 1 || digits == "" || (digits[0] == '0' && len(digits) > 1) {
 		return false
 	}
+	return strings.Trim(digits, "0123456789") == ""
 }
 
-func (this *Configurator) checkEnum(ftype reflect.Type, field reflect.Value, value interface{}, enumTag string) error {
-	parts := strings.Split(enumTag, ";")
-	var wasFound bool
-	for _, enumItem := range parts {
-		switch ftype.Kind() {
-		case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Int, reflect.Int32, reflect.Int64:
-			typedTag, err := strconv.ParseInt(enumItem, 10, 64)
-			if err != nil {
-				fmt.Errorf("Не смог распарсить часть тэга enum (%s) структуры в целочисленный тип (%w)", enumItem, err)
-			}
-			switch typedValue := value.(type) {
-			case uint:
-				if int64(typedValue) == int64(typedTag) {
-					wasFound = true
-				}
-			case uint64:
-				if int64(typedValue) == int64(typedTag) {
-					wasFound = true
-				}
-			case uint32:
-				if int64(typedValue) == int64(typedTag) {
-					wasFound = true
-				}
-			case int:
-				if int64(typedValue) == int64(typedTag) {
-					wasFound = true
-				}
-			case int64:
-				if int64(typedValue) == int64(typedTag) {
-					wasFound = true
-				}
-			case int32:
-				if int64(typedValue) == int64(typedTag) {
-					wasFound = true
-				}
-			default:
-				return fmt.Errorf("Невозможно сравнить значение целочисленного типа и нецелочисленного для валидации enum")
-			}
-		case reflect.Float64, reflect.Float32:
-			typedTag, err := strconv.ParseFloat(enumItem, 64)
-			if err != nil {
-				fmt.Errorf("Не смог распарсить часть тэга enum (%s) структуры в тип float (%w)", enumItem, err)
-			}
-			switch typedValue := value.(type) {
-			case uint:
-				if float64(typedValue) == float64(typedTag) {
-					wasFound = true
-				}
-			case uint64:
-				if float64(typedValue) == float64(typedTag) {
-					wasFound = true
-				}
-			case uint32:
-				if float64(typedValue) == float64(typedTag) {
-					wasFound = true
-				}
-			case int:
-				if float64(typedValue) == float64(typedTag) {
-					wasFound = true
-				}
-			case int64:
-				if float64(typedValue) == float64(typedTag) {
-					wasFound = true
-				}
-			case int32:
-				if float64(typedValue) == float64(typedTag) {
-					wasFound = true
-				}
-			case float32:
-				if float64(typedValue) == float64(typedTag) {
-					wasFound = true
-				}
-			case float64:
-				if float64(typedValue) == float64(typedTag) {
-					wasFound = true
-				}
-			default:
-				return fmt.Errorf("Невозможно сравнить значение вещественного (float) типа и невещественного для валидации enum")
-			}
-		case reflect.String:
-			switch typedValue := value.(type) {
-			case string:
-				if typedValue == enumItem {
-					wasFound = true
+/*	Тип значения узла для сообщений об ошибках  */
+func nodeValueType(node *yaml.Node) string {
+	value, _ := nodeValue(node)
+	return fmt.Sprintf("%T", value)
+}
+
+/*	Пары ключ-значение узла-словаря (ключи и значения чередуются) с учетом ключей слияния <<.
+**	Собственные ключи словаря приоритетнее ключей из сливаемых блоков  */
+func mappingPairs(node *yaml.Node) []*yaml.Node {
+	node = resolveAlias(node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	/*	Без ключей слияния пары совпадают с содержимым узла и не копируются  */
+	hasMerge := false
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if isMergeKey(node.Content[i]) == true {
+			hasMerge = true
+			break
+		}
+	}
+	if hasMerge == false {
+		return node.Content
+	}
+	pairs := make([]*yaml.Node, 0, len(node.Content))
+	var merged []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if isMergeKey(key) == true {
+			value = resolveAlias(value)
+			if value.Kind == yaml.SequenceNode {
+				for _, item := range value.Content {
+					merged = append(merged, mappingPairs(item)...)
 				}
-			default:
-				return fmt.Errorf("Невозможно сравнить значение строкового типа и нестрокового для валидации enum")
+			} else {
+				merged = append(merged, mappingPairs(value)...)
 			}
-		case reflect.Ptr:
-			return this.checkEnum(field.Type().Elem(), field, value, enumTag)
+			continue
 		}
+		pairs = append(pairs, key, value)
 	}
-	if wasFound == false {
-		return fmt.Errorf("Поле не соответствует ни одному из перечисленный в enum значений (%s)", enumTag)
+	for i := 0; i+1 < len(merged); i += 2 {
+		if pairIndex(pairs, merged[i].Value) < 0 {
+			pairs = append(pairs, merged[i], merged[i+1])
+		}
+	}
+	return pairs
+}
+
+func isMergeKey(key *yaml.Node) bool {
+	return key.Kind == yaml.ScalarNode && (key.Tag == "!!merge" || (key.Value == "<<" && key.ShortTag() == "!!merge"))
+}
+
+/*	Значение ключа узла-словаря или nil если ключ отсутствует  */
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	pairs := mappingPairs(node)
+	if i := pairIndex(pairs, key); i >= 0 {
+		return pairs[i+1]
 	}
 	return nil
 }
 
-func (this *Configurator) checkMinFieldValue(ftype reflect.Type, field reflect.Value, value interface{}, tagMinValue string) error {
-	switch ftype.Kind() {
-	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Int, reflect.Int32, reflect.Int64:
-		minValue, err := strconv.ParseInt(tagMinValue, 10, 64)
-		if err != nil {
-			fmt.Errorf("Не смог распарсить тэг min структуры в целочисленный тип (%w)", err)
+/*	Поиск узла по пути из ключей, разделенных точкой, и индексов списков, например
+**	Primary.Replicas[0].Host. Ключ, совпадающий с путем целиком, приоритетнее. nil если узла нет  */
+func lookupPath(node *yaml.Node, path string) *yaml.Node {
+	if value := mappingValue(node, path); value != nil || strings.ContainsAny(path, ".[") == false {
+		return value
+	}
+	for _, part := range strings.Split(path, ".") {
+		name, indexes, ok := splitIndexes(part)
+		if ok == false {
+			return nil
 		}
-		switch typedValue := value.(type) {
-		case uint:
-			if int64(typedValue) < int64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %d меньше значения %d заданного тэгом min заполняемой структуры", int(typedValue), int(minValue))
-			}
-		case uint64:
-			if int64(typedValue) < int64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %d меньше значения %d заданного тэгом min заполняемой структуры", int(typedValue), int(minValue))
-			}
-		case uint32:
-			if int64(typedValue) < int64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %d меньше значения %d заданного тэгом min заполняемой структуры", int(typedValue), int(minValue))
-			}
-		case int:
-			if int64(typedValue) < int64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %d меньше значения %d заданного тэгом min заполняемой структуры", int(typedValue), int(minValue))
-			}
-		case int64:
-			if int64(typedValue) < int64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %d меньше значения %d заданного тэгом min заполняемой структуры", int(typedValue), int(minValue))
+		if name != "" {
+			if node = mappingValue(node, name); node == nil {
+				return nil
 			}
-		case int32:
-			if int64(typedValue) < int64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %d меньше значения %d заданного тэгом min заполняемой структуры", int(typedValue), int(minValue))
-			}
-		default:
-			return fmt.Errorf("Невозможно сравнить значение целочисленного типа и нецелочисленного для проверки минимального значения")
-		}
-	case reflect.Float64, reflect.Float32:
-		minValue, err := strconv.ParseFloat(tagMinValue, 64)
-		if err != nil {
-			fmt.Errorf("Не смог распарсить тэг min структуры в тип float (%w)", err)
 		}
-		switch typedValue := value.(type) {
-		case uint:
-			if float64(typedValue) < float64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f меньше значения %f заданного тэгом min заполняемой структуры", float64(typedValue), float64(minValue))
+		for _, index := range indexes {
+			node = resolveAlias(node)
+			if node == nil || node.Kind != yaml.SequenceNode || index >= len(node.Content) {
+				return nil
 			}
-		case uint64:
-			if float64(typedValue) < float64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f меньше значения %f заданного тэгом min заполняемой структуры", float64(typedValue), float64(minValue))
-			}
-		case uint32:
-			if float64(typedValue) < float64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f меньше значения %f заданного тэгом min заполняемой структуры", float64(typedValue), float64(minValue))
-			}
-		case int:
-			if float64(typedValue) < float64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f меньше значения %f заданного тэгом min заполняемой структуры", float64(typedValue), float64(minValue))
-			}
-		case int64:
-			if float64(typedValue) < float64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f меньше значения %f заданного тэгом min заполняемой структуры", float64(typedValue), float64(minValue))
-			}
-		case int32:
-			if float64(typedValue) < float64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f меньше значения %f заданного тэгом min заполняемой структуры", float64(typedValue), float64(minValue))
-			}
-		case float64:
-			if float64(typedValue) < float64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f меньше значения %f заданного тэгом min заполняемой структуры", float64(typedValue), float64(minValue))
-			}
-		case float32:
-			if float64(typedValue) < float64(minValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f меньше значения %f заданного тэгом min заполняемой структуры", float64(typedValue), float64(minValue))
-			}
-		default:
-			return fmt.Errorf("Невозможно сравнить значение вещественного (float) типа и невещественного для проверки минимального значения")
+			node = node.Content[index]
 		}
-	case reflect.Ptr:
-		return this.checkMinFieldValue(field.Type().Elem(), field, value, tagMinValue)
 	}
-	return nil
+	return node
 }
 
-func (this *Configurator) checkMaxFieldValue(ftype reflect.Type, field reflect.Value, value interface{}, tagMaxValue string) error {
-	switch ftype.Kind() {
-	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Int, reflect.Int32, reflect.Int64:
-		maxValue, err := strconv.ParseInt(tagMaxValue, 10, 64)
-		if err != nil {
-			fmt.Errorf("Не смог распарсить тэг max структуры в целочисленный тип (%w)", err)
-		}
-		switch typedValue := value.(type) {
-		case uint:
-			if int64(typedValue) > int64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %d больше значения %d заданного тэгом max заполняемой структуры", int(typedValue), int(maxValue))
-			}
-		case uint64:
-			if int64(typedValue) > int64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %d больше значения %d заданного тэгом max заполняемой структуры", int(typedValue), int(maxValue))
-			}
-		case uint32:
-			if int64(typedValue) > int64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %d больше значения %d заданного тэгом max заполняемой структуры", int(typedValue), int(maxValue))
-			}
-		case int:
-			if int64(typedValue) > int64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %d больше значения %d заданного тэгом max заполняемой структуры", int(typedValue), int(maxValue))
-			}
-		case int64:
-			if int64(typedValue) > int64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %d больше значения %d заданного тэгом max заполняемой структуры", int(typedValue), int(maxValue))
-			}
-		case int32:
-			if int64(typedValue) > int64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %d больше значения %d заданного тэгом max заполняемой структуры", int(typedValue), int(maxValue))
-			}
-		default:
-			return fmt.Errorf("Невозможно сравнить значение целочисленного типа и нецелочисленного для проверки максимального значения")
+/*	Разбор части пути вида Replicas[0][1] на имя ключа и индексы  */
+func splitIndexes(part string) (string, []int, bool) {
+	open := strings.IndexByte(part, '[')
+	if open < 0 {
+		return part, nil, part != ""
+	}
+	name := part[:open]
+	var indexes []int
+	for rest := part[open:]; rest != ""; {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return "", nil, false
 		}
-	case reflect.Float64, reflect.Float32:
-		maxValue, err := strconv.ParseFloat(tagMaxValue, 64)
-		if err != nil {
-			fmt.Errorf("Не смог распарсить тэг max структуры в тип float (%w)", err)
+		index, err := strconv.Atoi(rest[1:end])
+		if err != nil || index < 0 {
+			return "", nil, false
 		}
-		switch typedValue := value.(type) {
-		case uint:
-			if float64(typedValue) > float64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f больше значения %f заданного тэгом max заполняемой структуры", float64(typedValue), float64(maxValue))
-			}
-		case uint64:
-			if float64(typedValue) > float64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f больше значения %f заданного тэгом max заполняемой структуры", float64(typedValue), float64(maxValue))
-			}
-		case uint32:
-			if float64(typedValue) > float64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f больше значения %f заданного тэгом max заполняемой структуры", float64(typedValue), float64(maxValue))
-			}
-		case int:
-			if float64(typedValue) > float64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f больше значения %f заданного тэгом max заполняемой структуры", float64(typedValue), float64(maxValue))
-			}
-		case int64:
-			if float64(typedValue) > float64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f больше значения %f заданного тэгом max заполняемой структуры", float64(typedValue), float64(maxValue))
-			}
-		case int32:
-			if float64(typedValue) > float64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f больше значения %f заданного тэгом max заполняемой структуры", float64(typedValue), float64(maxValue))
-			}
-		case float64:
-			if float64(typedValue) > float64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f больше значения %f заданного тэгом max заполняемой структуры", float64(typedValue), float64(maxValue))
-			}
-		case float32:
-			if float64(typedValue) > float64(maxValue) {
-				return fmt.Errorf("Значение поля в конфигурационном файле %f больше значения %f заданного тэгом max заполняемой структуры", float64(typedValue), float64(maxValue))
-			}
-		default:
-			return fmt.Errorf("Невозможно сравнить значение вещественного (float) типа и невещественного для проверки максимального значения")
+		indexes = append(indexes, index)
+		rest = rest[end+1:]
+	}
+	return name, indexes, true
+}
+
+func pairIndex(pairs []*yaml.Node, key string) int {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i].Value == key {
+			return i
 		}
-	case reflect.Ptr:
-		return this.checkMaxFieldValue(field.Type().Elem(), field, value, tagMaxValue)
 	}
-	return nil
+	return -1
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
 }
 
-/*	Получение значений для простых типов  */
+/*	Получение значений для простых типов. В строгом режиме (WithStrictTypes) значение
+**	должно совпадать с видом поля: строки, числа и логические значения не преобразуются друг в друга  */
 func (this *Configurator) primitiveType(ftype reflect.Type, value interface{}, tag reflect.StructTag) (reflect.Value, error) {
 	switch ftype.Kind() {
 	case reflect.String:
-		switch typedValue := value.(type) {
-		case string:
-			return reflect.ValueOf(string(typedValue)), nil
-		case int:
-			return reflect.ValueOf(strconv.Itoa(typedValue)), nil
-		case float64:
-			return reflect.ValueOf(strconv.FormatFloat(typedValue, 'E', -1, 64)), nil
-		case bool:
-			return reflect.ValueOf(strconv.FormatBool(typedValue)), nil
-		default:
-			return reflect.ValueOf(string("")), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
-		}
-	case reflect.Uint:
-		switch typedValue := value.(type) {
-		case string:
-			uint64Val, err := strconv.ParseUint(typedValue, 10, 64)
+		return stringType(ftype, value, this.strictTypes)
+	case reflect.Int64:
+		/*	time.Duration по сути является типом int64.
+		**	парсим длительность проверив тип вот таким костыльным образом */
+		if typedValue, ok := value.(string); ok == true && ftype.String() == "time.Duration" {
+			dur, err := time.ParseDuration(typedValue)
 			if err != nil {
-				return reflect.ValueOf(uint(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
+				return reflect.ValueOf(int(0)), typeError(ftype, fmt.Sprintf("%T", value))
 			}
-			return reflect.ValueOf(uint(uint64Val)), nil
-		case int:
-			return reflect.ValueOf(uint(typedValue)), nil
-		default:
-			return reflect.ValueOf(uint(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
+			return reflect.ValueOf(dur), nil
 		}
-	case reflect.Uint64:
-		switch typedValue := value.(type) {
-		case string:
-			uint64Val, err := strconv.ParseUint(typedValue, 10, 64)
-			if err != nil {
-				return reflect.ValueOf(uint64(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
-			}
-			return reflect.ValueOf(uint64Val), nil
-		case int:
-			return reflect.ValueOf(uint64(typedValue)), nil
-		default:
-			return reflect.ValueOf(uint64(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
+		return signedType(ftype, value, this.strictTypes)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return signedType(ftype, value, this.strictTypes)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return unsignedType(ftype, value, this.strictTypes)
+	case reflect.Bool:
+		return boolType(ftype, value, this.strictTypes)
+	case reflect.Float32, reflect.Float64:
+		return floatType(ftype, value, this.strictTypes)
+	default:
+		return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
+	}
+}
+
+/*	Значение для строкового поля. Числа и логические значения записываются
+**	в обычной (не экспоненциальной) форме, в строгом режиме являются ошибкой  */
+func stringType(ftype reflect.Type, value interface{}, strict bool) (reflect.Value, error) {
+	var stringVal string
+	switch typedValue := value.(type) {
+	case string:
+		stringVal = typedValue
+	case int, int64, uint64, float64, bool:
+		if strict == true {
+			return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
 		}
-	case reflect.Uint32:
-		switch typedValue := value.(type) {
-		case string:
-			uint64Val, err := strconv.ParseUint(typedValue, 10, 64)
-			if err != nil {
-				return reflect.ValueOf(uint32(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
-			}
-			return reflect.ValueOf(uint32(uint64Val)), nil
-		case int:
-			return reflect.ValueOf(uint32(typedValue)), nil
+		switch number := typedValue.(type) {
+		case float64:
+			stringVal = strconv.FormatFloat(number, 'f', -1, 64)
 		default:
-			return reflect.ValueOf(uint32(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
+			stringVal = fmt.Sprint(number)
 		}
-	case reflect.Int:
-		switch typedValue := value.(type) {
-		case string:
-			int64Val, err := strconv.ParseInt(typedValue, 10, 64)
-			if err != nil {
-				return reflect.ValueOf(int(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
-			}
-			return reflect.ValueOf(int64Val), nil
-		case int:
-			return reflect.ValueOf(int(typedValue)), nil
-		default:
-			return reflect.ValueOf(int(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
+	default:
+		return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
+	}
+	return reflect.ValueOf(stringVal).Convert(ftype), nil
+}
+
+/*	Логические значения YAML 1.1. yaml.v3 читает их как строки, но до перехода
+**	на него такие значения заполняли логические поля  */
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true, "on": true, "On": true, "ON": true,
+	"n": false, "N": false, "no": false, "No": false, "NO": false, "off": false, "Off": false, "OFF": false,
+}
+
+/*	Значение для логического поля. Вне строгого режима допускаются строки вида "true", "1", "f"
+**	и логические значения YAML 1.1 (yes, no, on, off, y, n)  */
+func boolType(ftype reflect.Type, value interface{}, strict bool) (reflect.Value, error) {
+	switch typedValue := value.(type) {
+	case bool:
+		return reflect.ValueOf(typedValue).Convert(ftype), nil
+	case string:
+		if strict == true {
+			return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
 		}
-	case reflect.Int64:
-		switch typedValue := value.(type) {
-		case string:
-			/*	time.Duration по сути является типом int64.
-			**	парсим длительность проверив тип вот таким костыльным образом */
-			if ftype.String() == "time.Duration" {
-				dur, err := time.ParseDuration(typedValue)
-				if err != nil {
-					return reflect.ValueOf(int(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
-				}
-				return reflect.ValueOf(dur), nil
-			} else {
-				int64Val, err := strconv.ParseInt(typedValue, 10, 64)
-				if err != nil {
-					return reflect.ValueOf(int64(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
-				}
-				return reflect.ValueOf(int64(int64Val)), nil
-			}
-		case int:
-			return reflect.ValueOf(int64(typedValue)), nil
-		default:
-			return reflect.ValueOf(int64(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
+		if boolVal, exists := yaml11Bools[typedValue]; exists == true {
+			return reflect.ValueOf(boolVal).Convert(ftype), nil
 		}
-	case reflect.Int32:
-		switch typedValue := value.(type) {
-		case string:
-			int64Val, err := strconv.ParseInt(typedValue, 10, 64)
-			if err != nil {
-				return reflect.ValueOf(int32(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
-			}
-			return reflect.ValueOf(int32(int64Val)), nil
-		case int:
-			return reflect.ValueOf(int32(typedValue)), nil
-		default:
-			return reflect.ValueOf(int32(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
+		boolVal, err := strconv.ParseBool(typedValue)
+		if err != nil {
+			return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
 		}
-	case reflect.Bool:
-		switch typedValue := value.(type) {
-		case string:
-			boolVal, err := strconv.ParseBool(typedValue)
-			if err != nil {
-				return reflect.ValueOf(bool(false)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
-			}
-			return reflect.ValueOf(bool(boolVal)), nil
-		case bool:
-			return reflect.ValueOf(bool(typedValue)), nil
-		default:
-			return reflect.ValueOf(bool(false)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
+		return reflect.ValueOf(boolVal).Convert(ftype), nil
+	default:
+		return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
+	}
+}
+
+/*	Значение для целочисленного поля со знаком. Выход за диапазон типа поля является ошибкой  */
+func signedType(ftype reflect.Type, value interface{}, strict bool) (reflect.Value, error) {
+	var int64Val int64
+	switch typedValue := value.(type) {
+	case string:
+		if strict == true {
+			return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
 		}
-	case reflect.Float64:
-		switch typedValue := value.(type) {
-		case string:
-			float64Val, err := strconv.ParseFloat(typedValue, 64)
-			if err != nil {
-				return reflect.ValueOf(float64(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
+		parsed, err := strconv.ParseInt(integerString(typedValue), 0, 64)
+		if errors.Is(err, strconv.ErrRange) == true {
+			return reflect.Value{}, rangeError(ftype, value, msgOverflow)
+		} else if err != nil {
+			return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
+		}
+		int64Val = parsed
+	case int:
+		int64Val = int64(typedValue)
+	case int64:
+		int64Val = typedValue
+	case uint64:
+		if typedValue > math.MaxInt64 {
+			return reflect.Value{}, rangeError(ftype, value, msgOverflow)
+		}
+		int64Val = int64(typedValue)
+	default:
+		return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
+	}
+	result := reflect.New(ftype).Elem()
+	if result.OverflowInt(int64Val) == true {
+		return reflect.Value{}, rangeError(ftype, value, msgOverflow)
+	}
+	result.SetInt(int64Val)
+	return result, nil
+}
+
+/*	Значение для беззнакового целочисленного поля. Отрицательные значения
+**	и выход за диапазон типа поля являются ошибкой  */
+func unsignedType(ftype reflect.Type, value interface{}, strict bool) (reflect.Value, error) {
+	var uint64Val uint64
+	switch typedValue := value.(type) {
+	case string:
+		if strict == true {
+			return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
+		}
+		if strings.HasPrefix(typedValue, "-") == true {
+			if _, err := strconv.ParseInt(integerString(typedValue), 0, 64); err == nil || errors.Is(err, strconv.ErrRange) == true {
+				return reflect.Value{}, rangeError(ftype, value, msgNegativeUnsigned)
 			}
-			return reflect.ValueOf(float64(float64Val)), nil
-		case float64:
-			return reflect.ValueOf(float64(typedValue)), nil
-		default:
-			return reflect.ValueOf(float64(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
+			return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
 		}
-	case reflect.Float32:
-		switch typedValue := value.(type) {
-		case string:
-			float64Val, err := strconv.ParseFloat(typedValue, 64)
-			if err != nil {
-				return reflect.ValueOf(float32(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
+		parsed, err := strconv.ParseUint(strings.TrimPrefix(integerString(typedValue), "+"), 0, 64)
+		if errors.Is(err, strconv.ErrRange) == true {
+			return reflect.Value{}, rangeError(ftype, value, msgOverflow)
+		} else if err != nil {
+			return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
+		}
+		uint64Val = parsed
+	case int:
+		if typedValue < 0 {
+			return reflect.Value{}, rangeError(ftype, value, msgNegativeUnsigned)
+		}
+		uint64Val = uint64(typedValue)
+	case int64:
+		if typedValue < 0 {
+			return reflect.Value{}, rangeError(ftype, value, msgNegativeUnsigned)
+		}
+		uint64Val = uint64(typedValue)
+	case uint64:
+		uint64Val = typedValue
+	default:
+		return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
+	}
+	result := reflect.New(ftype).Elem()
+	if result.OverflowUint(uint64Val) == true {
+		return reflect.Value{}, rangeError(ftype, value, msgOverflow)
+	}
+	result.SetUint(uint64Val)
+	return result, nil
+}
+
+/*	Значение для вещественного поля. Строки могут содержать разделители _
+**	и целые числа с префиксами 0x, 0o, 0b. Целые числа допускаются и в строгом режиме  */
+func floatType(ftype reflect.Type, value interface{}, strict bool) (reflect.Value, error) {
+	var float64Val float64
+	switch typedValue := value.(type) {
+	case string:
+		if strict == true {
+			return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
+		}
+		parsed, err := strconv.ParseFloat(typedValue, 64)
+		if err != nil {
+			int64Val, intErr := strconv.ParseInt(integerString(typedValue), 0, 64)
+			if intErr != nil {
+				return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
 			}
-			return reflect.ValueOf(float32(float64Val)), nil
-		case float64:
-			return reflect.ValueOf(float32(typedValue)), nil
-		default:
-			return reflect.ValueOf(float32(0)), typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
+			parsed = float64(int64Val)
 		}
+		float64Val = parsed
+	case float64:
+		float64Val = typedValue
+	case int:
+		float64Val = float64(typedValue)
+	case int64:
+		float64Val = float64(typedValue)
+	case uint64:
+		float64Val = float64(typedValue)
 	default:
-		return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value), this.lastAliasName)
+		return reflect.Value{}, typeError(ftype, fmt.Sprintf("%T", value))
 	}
+	result := reflect.New(ftype).Elem()
+	if math.IsInf(float64Val, 0) == false && result.OverflowFloat(float64Val) == true {
+		return reflect.Value{}, rangeError(ftype, value, msgOverflow)
+	}
+	result.SetFloat(float64Val)
+	return result, nil
 }
 
-func typeError(ftype reflect.Type, valueType, aliasName string) error {
-	return fmt.Errorf("Невозможно установить значение с типом %s в поле с типом %s (алиас %s)", valueType, ftype.String(), aliasName)
+/*	Подготовка строки из конфигурационника к разбору strconv с основанием 0: префиксы 0x, 0o, 0b
+**	и разделители _ сохраняются, а ведущие нули десятичной записи отбрасываются,
+**	чтобы строка "0755" по-прежнему означала десятичное число, а не восьмеричное  */
+func integerString(value string) string {
+	sign, digits := "", value
+	if strings.HasPrefix(digits, "-") == true || strings.HasPrefix(digits, "+") == true {
+		sign, digits = digits[:1], digits[1:]
+	}
+	if len(digits) > 1 && digits[0] == '0' && strings.IndexByte("xXoObB", digits[1]) < 0 {
+		digits = strings.TrimLeft(digits, "0_")
+		if digits == "" {
+			digits = "0"
+		}
+	}
+	return sign + digits
+}
+
+/*	Разбор вещественного числа из тэгов default, min, max и enum. Целые числа разбираются
+**	по правилам тэгов целочисленных полей (0644 - восьмеричное, как и без кавычек в yaml)  */
+func parseFloatString(value string, bitSize int) (float64, error) {
+	if int64Val, err := strconv.ParseInt(value, 0, 64); err == nil {
+		return float64(int64Val), nil
+	}
+	return strconv.ParseFloat(value, bitSize)
+}
+
+func typeError(ftype reflect.Type, valueType string) error {
+	return &FieldError{
+		Kind:     KindType,
+		Expected: ftype.String(),
+		Got:      valueType,
+		message:  msgTypeMismatch,
+		args:     []interface{}{valueType, ftype.String()},
+	}
 }
@@ -1,12 +1,27 @@
 package yaml
 
 import (
+	"embed"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
 	"os"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 )
 
+//go:embed sample_test.yaml
+var sampleFS embed.FS
+
+type sampleDatabaseType struct {
+	Name     string `conf:"Name"`
+	User     string `conf:"User"`
+	Password string `conf:"Password"`
+}
+
 func TestYamlConfigurator(t *testing.T) {
 	/*	Требуется присутствие файла sample_test.yaml текущей директории  */
 	t.Run("read file", func(t *testing.T) {
@@ -17,6 +32,82 @@ func TestYamlConfigurator(t *testing.T) {
 		}
 	})
 
+	t.Run("read bytes", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadBytes([]byte(`
+            Database:
+                Name: vuz_online
+                User: user
+                Password: password!
+        `)); err != nil {
+			t.Errorf("Error while reading bytes: %s", err)
+			t.FailNow()
+		}
+		var dto sampleDatabaseType
+		if err := config.ParseToStruct(&dto, "Database"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.Name != "vuz_online" {
+			t.Errorf("Fail: field %s expected %s got %s", "dto.Name", "vuz_online", dto.Name)
+		}
+	})
+
+	t.Run("read reader", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadReader(strings.NewReader("Database:\n  Name: from_reader\n  User: user\n  Password: password!\n")); err != nil {
+			t.Errorf("Error while reading reader: %s", err)
+			t.FailNow()
+		}
+		var dto sampleDatabaseType
+		if err := config.ParseToStruct(&dto, "Database"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.Name != "from_reader" {
+			t.Errorf("Fail: field %s expected %s got %s", "dto.Name", "from_reader", dto.Name)
+		}
+	})
+
+	t.Run("read fs", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"conf/app.yaml": &fstest.MapFile{Data: []byte("Database:\n  Name: from_fs\n  User: user\n  Password: password!\n")},
+		}
+		config := NewConfigurator()
+		if err := config.ReadFS(fsys, "conf/app.yaml"); err != nil {
+			t.Errorf("Error while reading fs: %s", err)
+			t.FailNow()
+		}
+		var dto sampleDatabaseType
+		if err := config.ParseToStruct(&dto, "Database"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.Name != "from_fs" {
+			t.Errorf("Fail: field %s expected %s got %s", "dto.Name", "from_fs", dto.Name)
+		}
+
+		if err := config.ReadFS(fsys, "conf/not_exist.yaml"); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		}
+	})
+
+	t.Run("read embed fs", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.ReadFS(sampleFS, "sample_test.yaml"); err != nil {
+			t.Errorf("Error while reading embed fs: %s", err)
+			t.FailNow()
+		}
+		var dto sampleDatabaseType
+		if err := config.ParseToStruct(&dto, "Database"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.Name != "vuz_online" || dto.User != "user" || dto.Password != "password!" {
+			t.Errorf("Fail: unexpected dto %#v", dto)
+		}
+	})
+
 	t.Run("TypedPrimitives", func(t *testing.T) {
 		config := NewConfigurator()
 		if err := config.setNewSource([]byte(`
@@ -1211,4 +1302,909 @@ func TestYamlConfigurator(t *testing.T) {
 			t.Errorf("Fail: DurationPtr expected %d got: %d", 21, dto.Duration.Milliseconds()/1000)
 		}
 	})
+
+	t.Run("Default tag", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.setNewSource([]byte(`
+            Alias:
+                Present: 7
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+
+		type DtoType struct {
+			Present     int            `conf:"Present" default:"42"`
+			IntVal      int            `conf:"IntVal" default:"42" min:"1" max:"100"`
+			UintPtrVal  *uint32        `conf:"UintPtrVal" default:"21"`
+			FloatVal    float64        `conf:"FloatVal" default:"3.1415"`
+			BoolVal     bool           `conf:"BoolVal" default:"true"`
+			StringVal   string         `conf:"StringVal" default:"info" enum:"debug;info"`
+			Duration    time.Duration  `conf:"Duration" default:"1m30s"`
+			DurationPtr *time.Duration `conf:"DurationPtr" default:"5s"`
+			SliceString []string       `conf:"SliceString" default:"a;b;c"`
+			SliceInt    []int64        `conf:"SliceInt" default:"1;2"`
+			EmptySlice  []string       `conf:"EmptySlice" default:""`
+		}
+		var dto DtoType
+		if err := config.ParseToStruct(&dto, "Alias"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.Present != 7 {
+			t.Errorf("Fail: field %s expected %d got %d", "dto.Present", 7, dto.Present)
+		}
+		if dto.IntVal != 42 {
+			t.Errorf("Fail: field %s expected %d got %d", "dto.IntVal", 42, dto.IntVal)
+		}
+		if dto.UintPtrVal == nil || *dto.UintPtrVal != 21 {
+			t.Errorf("Fail: field %s expected %d got %#v", "dto.UintPtrVal", 21, dto.UintPtrVal)
+		}
+		if dto.FloatVal != 3.1415 {
+			t.Errorf("Fail: field %s expected %f got %f", "dto.FloatVal", 3.1415, dto.FloatVal)
+		}
+		if dto.BoolVal != true {
+			t.Errorf("Fail: field %s expected %#v got %#v", "dto.BoolVal", true, dto.BoolVal)
+		}
+		if dto.StringVal != "info" {
+			t.Errorf("Fail: field %s expected %s got %s", "dto.StringVal", "info", dto.StringVal)
+		}
+		if dto.Duration != 90*time.Second {
+			t.Errorf("Fail: field %s expected %s got %s", "dto.Duration", 90*time.Second, dto.Duration)
+		}
+		if dto.DurationPtr == nil || *dto.DurationPtr != 5*time.Second {
+			t.Errorf("Fail: field %s expected %s got %#v", "dto.DurationPtr", 5*time.Second, dto.DurationPtr)
+		}
+		if len(dto.SliceString) != 3 || dto.SliceString[0] != "a" || dto.SliceString[2] != "c" {
+			t.Errorf("Fail: field %s got %#v", "dto.SliceString", dto.SliceString)
+		}
+		if len(dto.SliceInt) != 2 || dto.SliceInt[0] != 1 || dto.SliceInt[1] != 2 {
+			t.Errorf("Fail: field %s got %#v", "dto.SliceInt", dto.SliceInt)
+		}
+		if dto.EmptySlice == nil || len(dto.EmptySlice) != 0 {
+			t.Errorf("Fail: field %s got %#v", "dto.EmptySlice", dto.EmptySlice)
+		}
+
+		/*	Значение по умолчанию тоже должно проходить проверки min max enum  */
+		type MinDtoType struct {
+			IntVal int `conf:"IntVal" default:"0" min:"1"`
+		}
+		if err := config.ParseToStruct(&MinDtoType{}, "Alias"); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		} else if strings.Contains(err.Error(), " меньше значения ") == false {
+			t.Errorf("Fail: we expected another error %s", err)
+		}
+
+		type MaxDtoType struct {
+			FloatVal float64 `conf:"FloatVal" default:"10.5" max:"10"`
+		}
+		if err := config.ParseToStruct(&MaxDtoType{}, "Alias"); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		} else if strings.Contains(err.Error(), " больше значения ") == false {
+			t.Errorf("Fail: we expected another error %s", err)
+		}
+
+		type EnumDtoType struct {
+			StringVal string `conf:"StringVal" default:"trace" enum:"debug;info"`
+		}
+		if err := config.ParseToStruct(&EnumDtoType{}, "Alias"); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		} else if strings.Contains(err.Error(), "Поле не соответствует ни одному из перечисленный в enum значений") == false {
+			t.Errorf("Fail: we expected another error %s", err)
+		}
+
+		type InvalidDtoType struct {
+			IntVal int `conf:"IntVal" default:"forty two"`
+		}
+		if err := config.ParseToStruct(&InvalidDtoType{}, "Alias"); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		} else {
+			t.Logf("Success. %s", err)
+		}
+	})
+
+	t.Run("Conf tag options", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.setNewSource([]byte(`
+            Alias:
+                Present: 7
+                NullVal: null
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+
+		type DtoType struct {
+			Present     int     `conf:"Present,required"`
+			Optional    int     `conf:"Optional,optional"`
+			OptionalPtr *string `conf:"OptionalPtr,optional"`
+			OmitEmpty   string  `conf:"NullVal,omitempty"`
+			WithDefault int     `conf:"WithDefault,optional" default:"5"`
+		}
+		dto := DtoType{Optional: 3, OmitEmpty: "keep"}
+		if err := config.ParseToStruct(&dto, "Alias"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.Present != 7 {
+			t.Errorf("Fail: field %s expected %d got %d", "dto.Present", 7, dto.Present)
+		}
+		if dto.Optional != 3 {
+			t.Errorf("Fail: field %s expected %d got %d", "dto.Optional", 3, dto.Optional)
+		}
+		if dto.OptionalPtr != nil {
+			t.Errorf("Fail: field %s expected %#v got %#v", "dto.OptionalPtr", nil, dto.OptionalPtr)
+		}
+		if dto.OmitEmpty != "keep" {
+			t.Errorf("Fail: field %s expected %s got %s", "dto.OmitEmpty", "keep", dto.OmitEmpty)
+		}
+		if dto.WithDefault != 5 {
+			t.Errorf("Fail: field %s expected %d got %d", "dto.WithDefault", 5, dto.WithDefault)
+		}
+
+		/*	Без опций поле по-прежнему обязательно  */
+		type MandatoryDtoType struct {
+			Optional int `conf:"Optional"`
+		}
+		if err := config.ParseToStruct(&MandatoryDtoType{}, "Alias"); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		}
+
+		/*	Опция required отключает значение по умолчанию  */
+		type RequiredDtoType struct {
+			Optional int `conf:"Optional,required" default:"1"`
+		}
+		if err := config.ParseToStruct(&RequiredDtoType{}, "Alias"); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		}
+
+		/*	null без опции omitempty в поле int по-прежнему является ошибкой  */
+		type NullDtoType struct {
+			NullVal int `conf:"NullVal,optional"`
+		}
+		if err := config.ParseToStruct(&NullDtoType{}, "Alias"); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		}
+
+		type UnknownOptionDtoType struct {
+			Present int `conf:"Present,sometimes"`
+		}
+		if err := config.ParseToStruct(&UnknownOptionDtoType{}, "Alias"); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		} else if strings.Contains(err.Error(), "sometimes") == false {
+			t.Errorf("Fail: we expected another error %s", err)
+		}
+
+		type ConflictOptionsDtoType struct {
+			Present int `conf:"Present,required,optional"`
+		}
+		if err := config.ParseToStruct(&ConflictOptionsDtoType{}, "Alias"); err == nil {
+			t.Errorf("Fail: no error but it should be")
+		}
+	})
+
+	t.Run("Map key types", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.setNewSource([]byte(`
+            Alias:
+                Ports:
+                    80: http
+                    443: https
+                Weights:
+                    1.5: light
+                Broken:
+                    first: 1
+                    second: 2
+                    third: 3
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			Ports   map[int]string     `conf:"Ports"`
+			Weights map[float64]string `conf:"Weights"`
+		}
+		var dto DtoType
+		if err := config.ParseToStruct(&dto, "Alias"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if len(dto.Ports) != 2 || dto.Ports[80] != "http" || dto.Ports[443] != "https" {
+			t.Errorf("Fail: unexpected Ports %#v", dto.Ports)
+		}
+		if dto.Weights[1.5] != "light" {
+			t.Errorf("Fail: unexpected Weights %#v", dto.Weights)
+		}
+
+		/*	Ошибки элементов словаря перечисляются в порядке ключей в файле  */
+		type BrokenDtoType struct {
+			Broken map[int]string `conf:"Broken"`
+		}
+		err := config.ParseToStruct(&BrokenDtoType{}, "Alias")
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false || len(parseErrors) != 3 {
+			t.Errorf("Fail: unexpected error %v", err)
+			t.FailNow()
+		}
+		for i, path := range []string{"Broken.first", "Broken.second", "Broken.third"} {
+			var fieldErr *FieldError
+			if errors.As(parseErrors[i], &fieldErr) == false || fieldErr.Path != path {
+				t.Errorf("Fail: error %d expected path %s got %v", i, path, parseErrors[i])
+			}
+		}
+	})
+
+	t.Run("Anchors", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.setNewSource([]byte(`
+            Defaults: &defaults
+                Host: db.local
+                Port: 5432
+            Alias:
+                Primary:
+                    <<: *defaults
+                    Port: 6432
+                Replicas:
+                    - *defaults
+                    - <<: *defaults
+                      Host: replica.local
+                Hosts: &hosts [a, b]
+                SameHosts: *hosts
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type DatabaseType struct {
+			Host string `conf:"Host"`
+			Port int    `conf:"Port"`
+		}
+		type DtoType struct {
+			Primary   DatabaseType   `conf:"Primary"`
+			Replicas  []DatabaseType `conf:"Replicas"`
+			Hosts     []string       `conf:"Hosts"`
+			SameHosts []string       `conf:"SameHosts"`
+		}
+		var dto DtoType
+		if err := config.ParseToStruct(&dto, "Alias"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if dto.Primary.Host != "db.local" || dto.Primary.Port != 6432 {
+			t.Errorf("Fail: unexpected Primary %#v", dto.Primary)
+		}
+		if len(dto.Replicas) != 2 || dto.Replicas[0].Host != "db.local" || dto.Replicas[1].Host != "replica.local" || dto.Replicas[1].Port != 5432 {
+			t.Errorf("Fail: unexpected Replicas %#v", dto.Replicas)
+		}
+		if len(dto.SameHosts) != 2 || dto.SameHosts[1] != "b" {
+			t.Errorf("Fail: unexpected SameHosts %#v", dto.SameHosts)
+		}
+	})
+
+	t.Run("ParseValue", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.setNewSource([]byte(`
+            LogLevel: debug
+            Workers: 8
+            Hosts: [a, b]
+            Timeout: 5s
+            Limits:
+                rps: 100
+            Database:
+                Name: vuz_online
+                User: user
+                Password: password!
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		var logLevel string
+		if err := config.ParseValue(&logLevel, "LogLevel"); err != nil || logLevel != "debug" {
+			t.Errorf("Fail: LogLevel expected %s got %s (%v)", "debug", logLevel, err)
+		}
+		var workers uint
+		if err := config.ParseValue(&workers, "Workers"); err != nil || workers != 8 {
+			t.Errorf("Fail: Workers expected %d got %d (%v)", 8, workers, err)
+		}
+		var hosts []string
+		if err := config.ParseValue(&hosts, "Hosts"); err != nil || len(hosts) != 2 || hosts[1] != "b" {
+			t.Errorf("Fail: unexpected Hosts %#v (%v)", hosts, err)
+		}
+		var timeout time.Duration
+		if err := config.ParseValue(&timeout, "Timeout"); err != nil || timeout != 5*time.Second {
+			t.Errorf("Fail: Timeout expected %s got %s (%v)", 5*time.Second, timeout, err)
+		}
+		var limits map[string]int
+		if err := config.ParseValue(&limits, "Limits"); err != nil || limits["rps"] != 100 {
+			t.Errorf("Fail: unexpected Limits %#v (%v)", limits, err)
+		}
+		var database sampleDatabaseType
+		if err := config.ParseValue(&database, "Database"); err != nil || database.Name != "vuz_online" {
+			t.Errorf("Fail: unexpected Database %#v (%v)", database, err)
+		}
+
+		if err := config.ParseValue(&workers, "LogLevel"); errors.Is(err, ErrType) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+		if err := config.ParseToStruct(&database, "LogLevel"); errors.Is(err, ErrType) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+		if err := config.ParseValue(&logLevel, "NotExist"); errors.Is(err, ErrMissing) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+	})
+
+	t.Run("ParseAll", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.setNewSource([]byte(`
+            LogLevel: debug
+            Database:
+                Name: vuz_online
+                User: user
+                Password: password!
+            Logging:
+                Dir: /var/log/my_log
+                Rotate: often
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type LoggingType struct {
+			Dir    string `conf:"Dir"`
+			Rotate int    `conf:"Rotate"`
+		}
+		type AppConfigType struct {
+			LogLevel string             `conf:"LogLevel" enum:"debug;info"`
+			Workers  int                `conf:"Workers" default:"4"`
+			Database sampleDatabaseType `conf:"Database"`
+			Logging  LoggingType        `conf:"Logging"`
+			Metrics  *LoggingType       `conf:"Metrics,optional"`
+			Ignored  string
+		}
+		var dto AppConfigType
+		err := config.ParseAll(&dto)
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false || len(parseErrors) != 1 {
+			t.Errorf("Fail: unexpected error %v", err)
+			t.FailNow()
+		}
+		var fieldErr *FieldError
+		if errors.As(parseErrors[0], &fieldErr) == false || fieldErr.Alias != "Logging" || fieldErr.Path != "Rotate" {
+			t.Errorf("Fail: unexpected error %#v", parseErrors[0])
+		}
+		if dto.LogLevel != "debug" || dto.Workers != 4 || dto.Database.Name != "vuz_online" || dto.Logging.Dir != "/var/log/my_log" || dto.Metrics != nil {
+			t.Errorf("Fail: unexpected dto %#v", dto)
+		}
+
+		type MissingAliasType struct {
+			Database sampleDatabaseType `conf:"Database"`
+			Cache    LoggingType        `conf:"Cache"`
+		}
+		err = config.ParseAll(&MissingAliasType{})
+		if errors.As(err, &fieldErr) == false || fieldErr.Alias != "Cache" || errors.Is(err, ErrMissing) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		} else if strings.Contains(err.Error(), "Алиас отсутствует") == false {
+			t.Errorf("Fail: we expected another error %s", err)
+		}
+	})
+
+	t.Run("Dotted paths", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.setNewSource([]byte(`
+            Services:
+                Billing:
+                    Name: billing
+                    Database:
+                        Primary:
+                            Host: db.primary
+                        Replicas:
+                            - Host: replica1
+                              Port: 5432
+                            - Host: replica2
+                              Port: 6432
+                    a.b: literal
+            Hosts: [a, b]
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			Name        string `conf:"Name"`
+			PrimaryHost string `conf:"Database.Primary.Host"`
+			ReplicaHost string `conf:"Database.Replicas[1].Host"`
+			ReplicaPort int    `conf:"Database.Replicas[1].Port" max:"6000"`
+			Literal     string `conf:"a.b"`
+			Missing     string `conf:"Database.Replicas[5].Host,optional"`
+		}
+		var dto DtoType
+		err := config.ParseToStruct(&dto, "Services.Billing")
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) == false || fieldErr.Alias != "Services.Billing" || fieldErr.Path != "Database.Replicas[1].Port" || errors.Is(err, ErrMax) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+		if dto.Name != "billing" || dto.PrimaryHost != "db.primary" || dto.ReplicaHost != "replica2" || dto.Literal != "literal" {
+			t.Errorf("Fail: unexpected dto %#v", dto)
+		}
+
+		var host string
+		if err := config.ParseValue(&host, "Services.Billing.Database.Replicas[0].Host"); err != nil || host != "replica1" {
+			t.Errorf("Fail: host expected %s got %s (%v)", "replica1", host, err)
+		}
+		if err := config.ParseValue(&host, "Hosts[1]"); err != nil || host != "b" {
+			t.Errorf("Fail: host expected %s got %s (%v)", "b", host, err)
+		}
+		for _, aliasName := range []string{"Services.Shipping", "Hosts[2]", "Hosts[x]", "Services..Billing"} {
+			if err := config.ParseValue(&host, aliasName); errors.Is(err, ErrMissing) == false {
+				t.Errorf("Fail: unexpected error for %s: %v", aliasName, err)
+			}
+		}
+	})
+
+	t.Run("Generic Parse", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.setNewSource([]byte(`
+            LogLevel: debug
+            Hosts: [a, b]
+            Database:
+                Name: vuz_online
+                User: user
+                Password: password!
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		database, err := Parse[sampleDatabaseType](config, "Database")
+		if err != nil || database.Name != "vuz_online" {
+			t.Errorf("Fail: unexpected Database %#v (%v)", database, err)
+		}
+		databasePtr, err := Parse[*sampleDatabaseType](config, "Database")
+		if err != nil || databasePtr == nil || databasePtr.User != "user" {
+			t.Errorf("Fail: unexpected Database %#v (%v)", databasePtr, err)
+		}
+		if hosts := MustParse[[]string](config, "Hosts"); len(hosts) != 2 || hosts[0] != "a" {
+			t.Errorf("Fail: unexpected Hosts %#v", hosts)
+		}
+		if _, err := Parse[int](config, "LogLevel"); errors.Is(err, ErrType) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+
+		defer func() {
+			if recovered := recover(); recovered == nil {
+				t.Errorf("Fail: MustParse did not panic")
+			}
+		}()
+		MustParse[sampleDatabaseType](config, "NotExist")
+	})
+
+	t.Run("Invalid targets", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.setNewSource([]byte("Database:\n    Name: vuz_online\n")); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		var nilDto *sampleDatabaseType
+		var name string
+		for _, target := range []interface{}{nil, sampleDatabaseType{}, nilDto, &name} {
+			if err := config.ParseToStruct(target, "Database"); err == nil {
+				t.Errorf("Fail: no error for target %T", target)
+			} else {
+				t.Logf("Success. %s", err)
+			}
+			if err := config.ParseAll(target); err == nil {
+				t.Errorf("Fail: no error for target %T", target)
+			}
+		}
+		if err := config.ParseValue(name, "Database.Name"); err == nil || strings.Contains(err.Error(), "указателем") == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+		if err := config.ParseValue(&name, "Database.Name"); err != nil || name != "vuz_online" {
+			t.Errorf("Fail: name expected %s got %s (%v)", "vuz_online", name, err)
+		}
+	})
+
+	/*	Имеет смысл запускать с флагом -race  */
+	t.Run("Concurrent parsing", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.setNewSource([]byte(`
+            First:
+                Port: 0
+            Second:
+                Count: many
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type FirstType struct {
+			Port int `conf:"Port" min:"1"`
+		}
+		type SecondType struct {
+			Count int `conf:"Count"`
+		}
+		var wg sync.WaitGroup
+		errs := make(chan error, 200)
+		for i := 0; i < 50; i++ {
+			wg.Add(3)
+			go func() {
+				defer wg.Done()
+				var fieldErr *FieldError
+				if err := config.ParseToStruct(&FirstType{}, "First"); errors.As(err, &fieldErr) == false || fieldErr.Alias != "First" {
+					errs <- fmt.Errorf("unexpected error for First: %v", err)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				var fieldErr *FieldError
+				if err := config.ParseToStruct(&SecondType{}, "Second"); errors.As(err, &fieldErr) == false || fieldErr.Alias != "Second" {
+					errs <- fmt.Errorf("unexpected error for Second: %v", err)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				if err := config.MergeBytes([]byte("Third:\n    Port: 1\n")); err != nil {
+					errs <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Errorf("Fail: %s", err)
+		}
+		var third FirstType
+		if err := config.ParseToStruct(&third, "Third"); err != nil || third.Port != 1 {
+			t.Errorf("Fail: unexpected Third %#v (%v)", third, err)
+		}
+	})
+
+	t.Run("Integer kinds", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.setNewSource([]byte(`
+            Valid:
+                Int8Val: -128
+                Int16Val: 32767
+                Int8PtrVal: 127
+                Uint8Val: 255
+                Uint16Val: 65535
+                UintptrVal: 4096
+                Uint8StrVal: !!uint8 200
+            Invalid:
+                Int8Val: 128
+                Int16Val: !!int16 -40000
+                Int32Val: 2147483648
+                Int64Val: !!int64 9223372036854775808
+                Uint8Val: 256
+                Uint16Val: -1
+                UintVal: !!uint -5
+                Uint32Val: 4294967296
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type ValidType struct {
+			Int8Val     int8    `conf:"Int8Val" min:"-128"`
+			Int16Val    int16   `conf:"Int16Val"`
+			Int8PtrVal  *int8   `conf:"Int8PtrVal" max:"127"`
+			Uint8Val    uint8   `conf:"Uint8Val" enum:"1;255"`
+			Uint16Val   uint16  `conf:"Uint16Val"`
+			UintptrVal  uintptr `conf:"UintptrVal"`
+			Uint8StrVal uint8   `conf:"Uint8StrVal"`
+			Uint16Def   uint16  `conf:"Uint16Def" default:"8080"`
+		}
+		var valid ValidType
+		if err := config.ParseToStruct(&valid, "Valid"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		if valid.Int8Val != -128 || valid.Int16Val != 32767 || valid.Int8PtrVal == nil || *valid.Int8PtrVal != 127 {
+			t.Errorf("Fail: unexpected signed values %#v", valid)
+		}
+		if valid.Uint8Val != 255 || valid.Uint16Val != 65535 || valid.UintptrVal != 4096 || valid.Uint8StrVal != 200 || valid.Uint16Def != 8080 {
+			t.Errorf("Fail: unexpected unsigned values %#v", valid)
+		}
+
+		type InvalidType struct {
+			Int8Val   int8   `conf:"Int8Val"`
+			Int16Val  int16  `conf:"Int16Val"`
+			Int32Val  int32  `conf:"Int32Val"`
+			Int64Val  int64  `conf:"Int64Val"`
+			Uint8Val  uint8  `conf:"Uint8Val"`
+			Uint16Val uint16 `conf:"Uint16Val"`
+			UintVal   uint   `conf:"UintVal"`
+			Uint32Val uint32 `conf:"Uint32Val"`
+		}
+		var invalid InvalidType
+		err := config.ParseToStruct(&invalid, "Invalid")
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false || len(parseErrors) != 8 {
+			t.Errorf("Fail: unexpected error %v", err)
+			t.FailNow()
+		}
+		for _, fieldErr := range parseErrors {
+			if errors.Is(fieldErr, ErrRange) == false {
+				t.Errorf("Fail: expected range error got %s", fieldErr)
+			}
+		}
+		if invalid != (InvalidType{}) {
+			t.Errorf("Fail: fields changed on error %#v", invalid)
+		}
+		if strings.Contains(parseErrors[5].Error(), "Отрицательное значение -1") == false {
+			t.Errorf("Fail: we expected another error %s", parseErrors[5])
+		}
+	})
+
+	t.Run("Integer notations", func(t *testing.T) {
+		config := NewConfigurator()
+		if err := config.setNewSource([]byte(`
+            Alias:
+                Hex: 0x1F
+                Octal: 0o17
+                Binary: 0b101
+                Separated: 1_000_000
+                MaxUint64: 18446744073709551615
+                BigInt64: !!int64 9223372036854775807
+                HexString: "0xFF"
+                OctalString: !!uint16 0o755
+                BinaryString: '-0b101'
+                SeparatedString: "1_000_000"
+                DecimalString: "0755"
+                FloatSeparated: "1_000.5"
+                FloatHex: "0x10"
+                FloatFromInt: 3
+                FloatFromUint64: 18446744073709551615
+                Float32Overflow: 1e39
+                Int64Overflow: 9223372036854775808
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			Hex             uint8   `conf:"Hex"`
+			Octal           int16   `conf:"Octal"`
+			Binary          int     `conf:"Binary" enum:"0b101"`
+			Separated       uint32  `conf:"Separated" max:"1_000_000"`
+			MaxUint64       uint64  `conf:"MaxUint64"`
+			BigInt64        int64   `conf:"BigInt64"`
+			HexString       uint    `conf:"HexString"`
+			OctalString     uint16  `conf:"OctalString"`
+			BinaryString    int8    `conf:"BinaryString"`
+			SeparatedString int     `conf:"SeparatedString"`
+			DecimalString   int     `conf:"DecimalString"`
+			FloatSeparated  float64 `conf:"FloatSeparated"`
+			FloatHex        float32 `conf:"FloatHex"`
+			FloatFromInt    float64 `conf:"FloatFromInt"`
+			FloatFromUint64 float64 `conf:"FloatFromUint64"`
+			DefaultHex      uint16  `conf:"DefaultHex" default:"0xFFFF"`
+		}
+		var dto DtoType
+		if err := config.ParseToStruct(&dto, "Alias"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		expected := DtoType{
+			Hex:             31,
+			Octal:           15,
+			Binary:          5,
+			Separated:       1000000,
+			MaxUint64:       18446744073709551615,
+			BigInt64:        9223372036854775807,
+			HexString:       255,
+			OctalString:     493,
+			BinaryString:    -5,
+			SeparatedString: 1000000,
+			DecimalString:   755,
+			FloatSeparated:  1000.5,
+			FloatHex:        16,
+			FloatFromInt:    3,
+			FloatFromUint64: 18446744073709551615,
+			DefaultHex:      65535,
+		}
+		if dto != expected {
+			t.Errorf("Fail: expected %#v got %#v", expected, dto)
+		}
+
+		type OverflowType struct {
+			MaxUint64       int64   `conf:"MaxUint64"`
+			Float32Overflow float32 `conf:"Float32Overflow"`
+			Int64Overflow   int64   `conf:"Int64Overflow"`
+			BinaryString    uint    `conf:"BinaryString"`
+		}
+		err := config.ParseToStruct(&OverflowType{}, "Alias")
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false || len(parseErrors) != 4 || errors.Is(err, ErrRange) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+		for _, fieldErr := range parseErrors {
+			if errors.Is(fieldErr, ErrRange) == false {
+				t.Errorf("Fail: expected range error got %s", fieldErr)
+			}
+		}
+	})
+
+	t.Run("Leading zero", func(t *testing.T) {
+		/*	Без кавычек yaml читает 0644 как восьмеричное число, тэги разбираются так же.
+		**	Строки в кавычках остаются десятичными  */
+		config := NewConfigurator()
+		if err := config.setNewSource([]byte(`
+            File:
+                Mode: 0644
+                Quoted: "0644"
+                Ratio: 0644
+                QuotedRatio: "0644"
+                Port: '08080'
+            Default: {}
+        `)); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		type DtoType struct {
+			Mode        uint32  `conf:"Mode" default:"0644" min:"0600" enum:"0644;0755"`
+			Quoted      int     `conf:"Quoted,optional"`
+			Ratio       float64 `conf:"Ratio" default:"0644" max:"0644"`
+			QuotedRatio float32 `conf:"QuotedRatio,optional"`
+			Port        uint16  `conf:"Port,optional"`
+		}
+		for _, alias := range []string{"File", "Default"} {
+			var dto DtoType
+			if err := config.ParseToStruct(&dto, alias); err != nil {
+				t.Errorf("Error while filling config: %s", err)
+				continue
+			}
+			if dto.Mode != 0644 || dto.Ratio != 0644 {
+				t.Errorf("Fail: %s unexpected dto %#v", alias, dto)
+			}
+			if alias == "File" && (dto.Quoted != 644 || dto.QuotedRatio != 644 || dto.Port != 8080) {
+				t.Errorf("Fail: %s unexpected dto %#v", alias, dto)
+			}
+		}
+	})
+
+	t.Run("Strict types", func(t *testing.T) {
+		source := []byte(`
+            Alias:
+                Name: service
+                Port: 8080
+                Ratio: 2
+                Enabled: true
+                Timeout: 5s
+            Coerced:
+                Name: 42
+                Port: "8080"
+                Ratio: "0.5"
+                Enabled: "true"
+            Floats:
+                Small: 0.00001
+                Large: 4.2e+21
+                Plain: 42.5
+        `)
+		type LevelType string
+		type DtoType struct {
+			Name    LevelType     `conf:"Name"`
+			Port    uint16        `conf:"Port"`
+			Ratio   float64       `conf:"Ratio"`
+			Enabled bool          `conf:"Enabled"`
+			Timeout time.Duration `conf:"Timeout"`
+			Retries int           `conf:"Retries" default:"3"`
+			Level   string        `conf:"Level" default:"42"`
+			Scale   float32       `conf:"Scale" default:"1"`
+			Debug   bool          `conf:"Debug" default:"false"`
+		}
+		strict := NewConfigurator(WithStrictTypes())
+		if err := strict.setNewSource(source); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		var dto DtoType
+		if err := strict.ParseToStruct(&dto, "Alias"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+			t.FailNow()
+		}
+		expected := DtoType{Name: "service", Port: 8080, Ratio: 2, Enabled: true, Timeout: 5 * time.Second, Retries: 3, Level: "42", Scale: 1}
+		if dto != expected {
+			t.Errorf("Fail: expected %#v got %#v", expected, dto)
+		}
+
+		type CoercedType struct {
+			Name    string  `conf:"Name"`
+			Port    int     `conf:"Port"`
+			Ratio   float64 `conf:"Ratio"`
+			Enabled bool    `conf:"Enabled"`
+		}
+		err := strict.ParseToStruct(&CoercedType{}, "Coerced")
+		var parseErrors ParseErrors
+		if errors.As(err, &parseErrors) == false || len(parseErrors) != 4 || errors.Is(err, ErrType) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+
+		lenient := NewConfigurator()
+		if err := lenient.setNewSource(source); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		var coerced CoercedType
+		if err := lenient.ParseToStruct(&coerced, "Coerced"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+		} else if coerced != (CoercedType{Name: "42", Port: 8080, Ratio: 0.5, Enabled: true}) {
+			t.Errorf("Fail: unexpected dto %#v", coerced)
+		}
+
+		type FloatsType struct {
+			Small string `conf:"Small"`
+			Large string `conf:"Large"`
+			Plain string `conf:"Plain"`
+		}
+		var floats FloatsType
+		if err := lenient.ParseToStruct(&floats, "Floats"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+		} else if floats != (FloatsType{Small: "0.00001", Large: "4200000000000000000000", Plain: "42.5"}) {
+			t.Errorf("Fail: unexpected dto %#v", floats)
+		}
+	})
+
+	t.Run("Scalar values", func(t *testing.T) {
+		/*	Быстрое чтение скаляров должно давать то же, что и декодер yaml  */
+		var root yaml.Node
+		if err := yaml.Unmarshal([]byte(`[text, "quoted", 42, -7, +3, 0, 0755, 0x1F, 1_000, 9223372036854775808,
+			1.5, -2e3, .inf, 1_000.5, true, false, True, yes, null, ~, !!null '', !!str 42, !!int "12", !!float 3]`), &root); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		for _, node := range root.Content[0].Content {
+			var expected interface{}
+			if err := node.Decode(&expected); err != nil {
+				t.Errorf("Error while decoding %q: %s", node.Value, err)
+				continue
+			}
+			value, err := nodeValue(node)
+			if err != nil || fmt.Sprintf("%T %v", value, value) != fmt.Sprintf("%T %v", expected, expected) {
+				t.Errorf("Fail: %q expected %T %v got %T %v (%v)", node.Value, expected, expected, value, value, err)
+			}
+		}
+	})
+
+	t.Run("YAML 1.1 booleans", func(t *testing.T) {
+		source := []byte(`
+            Alias:
+                On: on
+                Yes: yes
+                Y: Y
+                Off: OFF
+                No: no
+                N: n
+                Quoted: "Yes"
+                Wrong: maybe
+        `)
+		type DtoType struct {
+			On     bool `conf:"On"`
+			Yes    bool `conf:"Yes"`
+			Y      bool `conf:"Y"`
+			Off    bool `conf:"Off"`
+			No     bool `conf:"No"`
+			N      bool `conf:"N"`
+			Quoted bool `conf:"Quoted"`
+		}
+		config := NewConfigurator()
+		if err := config.ReadBytes(source); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		dto := DtoType{Off: true, No: true, N: true}
+		if err := config.ParseToStruct(&dto, "Alias"); err != nil {
+			t.Errorf("Error while filling config: %s", err)
+		} else if dto != (DtoType{On: true, Yes: true, Y: true, Quoted: true}) {
+			t.Errorf("Fail: unexpected dto %#v", dto)
+		}
+		var wrong bool
+		if err := config.ParseValue(&wrong, "Alias.Wrong"); errors.Is(err, ErrType) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+
+		/*	В строгом режиме yaml.v3 считает такие значения строками  */
+		strict := NewConfigurator(WithStrictTypes())
+		if err := strict.ReadBytes(source); err != nil {
+			t.Errorf("Error while reading source yaml: %s", err)
+			t.FailNow()
+		}
+		if err := strict.ParseToStruct(&DtoType{}, "Alias"); errors.Is(err, ErrType) == false {
+			t.Errorf("Fail: unexpected error %v", err)
+		}
+	})
 }